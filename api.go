@@ -1,40 +1,208 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
-	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/madeindra/interview-app/internal/audiocache"
 	"github.com/madeindra/interview-app/internal/language"
 	"github.com/madeindra/interview-app/internal/model"
 	oaiModel "github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/prompt"
+	"github.com/madeindra/interview-app/internal/provider"
+	"github.com/madeindra/interview-app/internal/speech"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// maxHistoryPage bounds the turns returned by a single GetHistory call
+const maxHistoryPage = 100
+
 func (a *App) AreKeyExist() (bool, error) {
-	return a.model.AreKeyExist()
+	setting, err := a.model.GetSetting()
+	if err != nil {
+		return false, fmt.Errorf("failed to get setting: %v", err)
+	}
+
+	return hasKeys(setting), nil
 }
 
 func (a *App) UpdateAPIKeys(oaiKey, elKey string) error {
-	return a.model.UpdateAPIKeys(oaiKey, elKey)
+	if err := a.model.UpdateAPIKeys(oaiKey, elKey); err != nil {
+		return err
+	}
+
+	return a.loadProviders()
 }
 
-func (a *App) Status() (model.StatusResponse, error) {
-	oaiKey, _, err := a.model.GetAPIKey()
+func (a *App) UpdateAzureKey(azureKey string) error {
+	if azureKey == "" {
+		return fmt.Errorf("azure key is required")
+	}
+
+	if err := a.model.UpdateAzureKey(azureKey); err != nil {
+		return fmt.Errorf("failed to update azure key: %v", err)
+	}
+
+	return a.loadProviders()
+}
+
+func (a *App) UpdateAnthropicKey(anthropicKey string) error {
+	if anthropicKey == "" {
+		return fmt.Errorf("anthropic key is required")
+	}
+
+	if err := a.model.UpdateAnthropicKey(anthropicKey); err != nil {
+		return fmt.Errorf("failed to update anthropic key: %v", err)
+	}
+
+	return a.loadProviders()
+}
+
+func (a *App) GetSettings() (model.Setting, error) {
+	return a.model.GetSetting()
+}
+
+func (a *App) UpdateSettings(setting model.Setting) error {
+	saved, err := a.model.GetSetting()
+	if err != nil {
+		return fmt.Errorf("failed to get setting: %v", err)
+	}
+
+	// the keys never reach the frontend, they are only updated by UpdateAPIKeys
+	setting.OpenAIKey, setting.ElevenLabsKey = saved.OpenAIKey, saved.ElevenLabsKey
+	setting.AzureKey, setting.AnthropicKey = saved.AzureKey, saved.AnthropicKey
+
+	if err := validateSetting(setting); err != nil {
+		return err
+	}
+
+	p, err := newProviders(setting)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(a.baseContext(), statusTimeout)
+	defer cancel()
+
+	if err := checkModels(ctx, p, setting); err != nil {
+		return err
+	}
+
+	if err := a.model.UpdateSetting(setting); err != nil {
+		return fmt.Errorf("failed to update setting: %v", err)
+	}
+
+	return a.loadProviders()
+}
+
+// ListModels returns the models offered by the provider of the capability: chat, transcript or speech
+func (a *App) ListModels(capability string) ([]string, error) {
+	p, err := a.getProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.baseContext(), statusTimeout)
+	defer cancel()
+
+	models, err := p.listModels(ctx, provider.Capability(capability))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	return models, nil
+}
+
+// ListVoices returns the elevenlabs voices that can be picked for an interview
+func (a *App) ListVoices() ([]model.Voice, error) {
+	p, err := a.getProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.baseContext(), statusTimeout)
+	defer cancel()
+
+	voices, err := p.listVoices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list voices: %w", err)
+	}
+
+	return voices, nil
+}
+
+// PreviewVoice speaks a short sample with the provider and voice, repeated previews come from the audio cache
+func (a *App) PreviewVoice(providerName, voiceID, text string) (string, error) {
+	text = sanitizeString(strings.TrimSpace(text))
+	if text == "" {
+		text = previewText
+	}
+
+	if utf8.RuneCountInString(text) > previewMaxLength {
+		return "", fmt.Errorf("preview text must be at most %d characters", previewMaxLength)
+	}
+
+	setting, err := a.model.GetSetting()
 	if err != nil {
-		return model.StatusResponse{}, fmt.Errorf("failed to get api key: %v", err)
+		return "", fmt.Errorf("failed to get setting: %v", err)
+	}
+
+	setting = previewSetting(setting, providerName, voiceID)
+	if err := validateVoice(setting); err != nil {
+		return "", err
 	}
 
-	isKeyValid, err := a.oaiAPI.IsKeyValid(oaiKey)
+	preview, err := newSpeechSynthesizer(setting)
 	if err != nil {
-		return model.StatusResponse{}, fmt.Errorf("failed to check api key: %v", err)
+		return "", err
 	}
 
-	status, err := a.oaiAPI.Status()
+	// previews belong to no interview
+	synthesizer := audiocache.Wrap(preview, a.audioCache(setting), func(text string) {
+		a.recordSpeechUsage("", preview, text)
+	})
+
+	ctx, cancel := context.WithTimeout(a.baseContext(), speechTimeout)
+	defer cancel()
+
+	audio, err := speech.Synthesize(ctx, synthesizer, text)
 	if err != nil {
-		return model.StatusResponse{}, fmt.Errorf("failed to get api status: %v", err)
+		return "", fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(audio), nil
+}
+
+func (a *App) Status() (model.StatusResponse, error) {
+	p, err := a.getProviders()
+	if err != nil {
+		return model.StatusResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.baseContext(), statusTimeout)
+	defer cancel()
+
+	// providers that can't validate their key are assumed to be valid
+	isKeyValid := true
+	if validator, ok := p.chat.primary().(provider.KeyValidator); ok {
+		isKeyValid, err = validator.IsKeyValid(ctx)
+		if err != nil {
+			return model.StatusResponse{}, fmt.Errorf("failed to check api key: %w", err)
+		}
+	}
+
+	status := oaiModel.STATUS_UNKNOWN
+	if reporter, ok := p.chat.primary().(provider.StatusReporter); ok {
+		status, err = reporter.Status(ctx)
+		if err != nil {
+			return model.StatusResponse{}, fmt.Errorf("failed to get api status: %w", err)
+		}
 	}
 
 	var apiState *bool
@@ -58,42 +226,48 @@ func (a *App) Status() (model.StatusResponse, error) {
 	return response, nil
 }
 
-func (a *App) StartChat(role string, skills []string, lang string) (model.StartChatResponse, error) {
-	oaiKey, elKey, err := a.model.GetAPIKey()
+// StartChat begins the interview, the voice options pick the elevenlabs voice kept for the whole session
+func (a *App) StartChat(role string, skills []string, lang string, voice model.VoiceOptions) (model.StartChatResponse, error) {
+	p, err := a.getProviders()
 	if err != nil {
-		return model.StartChatResponse{}, fmt.Errorf("failed to get api key: %v", err)
+		return model.StartChatResponse{}, err
 	}
 
-	chatLanguage := a.oaiAPI.GetDefaultTranscriptLanguage()
-	if lang != "" {
-		chatLanguage = language.GetLanguage(lang)
+	setting, err := a.model.GetSetting()
+	if err != nil {
+		return model.StartChatResponse{}, fmt.Errorf("failed to get setting: %v", err)
 	}
 
-	systempPrompt, err := a.oaiAPI.GetSystemPrompt(role, skills, chatLanguage)
-	if err != nil {
-		return model.StartChatResponse{}, fmt.Errorf("failed to get system prompt: %v", err)
+	// a model retired since it was saved would only fail halfway through the interview
+	checkCtx, cancelCheck := context.WithTimeout(a.baseContext(), statusTimeout)
+	defer cancelCheck()
+
+	if err := checkModels(checkCtx, p, setting); err != nil {
+		return model.StartChatResponse{}, err
 	}
 
-	initialText, err := a.oaiAPI.GetInitialChat(role, chatLanguage)
+	p, warnings, err := a.applyBudget("", p)
 	if err != nil {
-		return model.StartChatResponse{}, fmt.Errorf("failed to get initial text: %v", err)
+		return model.StartChatResponse{}, err
 	}
 
-	var initialAudio io.Reader
-	if a.oaiAPI.IsSpeechAvailable(chatLanguage) {
-		initialAudio, _ = a.oaiAPI.Speechify(oaiKey, sanitizeString(initialText))
-	} else {
-		initialAudio, _ = a.elAPI.Speechify(elKey, sanitizeString(initialText))
+	if err := validateVoiceOptions(checkCtx, p, voice); err != nil {
+		return model.StartChatResponse{}, err
 	}
 
-	var audioBase64 string
-	if initialAudio != nil {
-		audioByte, err := io.ReadAll(initialAudio)
-		if err != nil {
-			return model.StartChatResponse{}, fmt.Errorf("failed to read speech: %v", err)
-		}
+	chatLanguage := string(language.LANGUAGE_DEFAULT)
+	if lang != "" {
+		chatLanguage = language.GetLanguage(lang)
+	}
 
-		audioBase64 = base64.StdEncoding.EncodeToString(audioByte)
+	systempPrompt, err := prompt.GetSystemPrompt(role, skills, chatLanguage)
+	if err != nil {
+		return model.StartChatResponse{}, fmt.Errorf("failed to get system prompt: %v", err)
+	}
+
+	initialText, err := prompt.GetInitialChat(role, chatLanguage)
+	if err != nil {
+		return model.StartChatResponse{}, fmt.Errorf("failed to get initial text: %v", err)
 	}
 
 	plainSecret := generateRandom()
@@ -102,16 +276,28 @@ func (a *App) StartChat(role string, skills []string, lang string) (model.StartC
 		return model.StartChatResponse{}, fmt.Errorf("failed to create hash: %v", err)
 	}
 
-	newUser, err := a.model.CreateChatUser(hashed, chatLanguage)
+	newUser, err := a.model.CreateChatUser(newSession(p, hashed, chatLanguage, role, skills, voice))
 	if err != nil {
 		return model.StartChatResponse{}, fmt.Errorf("failed to create new chat: %v", err)
 	}
 
-	if _, err := a.model.CreateChat(newUser.ID, string(oaiModel.ROLE_SYSTEM), systempPrompt, audioBase64); err != nil {
-		return model.StartChatResponse{}, fmt.Errorf("failed to create chat: %v", err)
+	// the greeting only depends on the role, so repeated interviews are spoken from the cache
+	var initialAudio []byte
+	var audioBase64, speechError string
+	if synthesizer := a.speechFor(p, newUser.ID, chatLanguage, voice); synthesizer != nil {
+		initialAudio, err = speech.Synthesize(a.baseContext(), synthesizer, sanitizeString(initialText))
+		if err != nil {
+			log.Default().Println("failed to synthesize speech:", err)
+			speechError = fmt.Sprintf("failed to synthesize speech: %v", err)
+		} else {
+			audioBase64 = base64.StdEncoding.EncodeToString(initialAudio)
+		}
 	}
 
-	if _, err := a.model.CreateChat(newUser.ID, string(oaiModel.ROLE_ASSISTANT), initialText, audioBase64); err != nil {
+	if _, err := a.model.CreateChats(newUser.ID,
+		model.NewChat{Role: string(oaiModel.ROLE_SYSTEM), Text: systempPrompt},
+		model.NewChat{Role: string(oaiModel.ROLE_ASSISTANT), Text: initialText, Audio: a.storeAudio(initialAudio, 0)},
+	); err != nil {
 		return model.StartChatResponse{}, fmt.Errorf("failed to create chat: %v", err)
 	}
 
@@ -119,9 +305,11 @@ func (a *App) StartChat(role string, skills []string, lang string) (model.StartC
 		ID:       newUser.ID,
 		Secret:   plainSecret,
 		Language: lang,
+		Warnings: warnings,
 		Chat: model.Chat{
-			Text:  initialText,
-			Audio: audioBase64,
+			Text:        initialText,
+			Audio:       audioBase64,
+			SpeechError: speechError,
 		},
 	}
 
@@ -129,9 +317,9 @@ func (a *App) StartChat(role string, skills []string, lang string) (model.StartC
 }
 
 func (a *App) AnswerChat(userID, userSecret string, audioData []byte) (model.AnswerChatResponse, error) {
-	apiKey, elKey, err := a.model.GetAPIKey()
+	p, err := a.getProviders()
 	if err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to get api key: %v", err)
+		return model.AnswerChatResponse{}, err
 	}
 
 	user, err := a.model.GetChatUser(userID)
@@ -143,23 +331,32 @@ func (a *App) AnswerChat(userID, userSecret string, audioData []byte) (model.Ans
 		return model.AnswerChatResponse{}, fmt.Errorf("invalid user secret")
 	}
 
-	entry, err := a.model.GetChatsByChatUserID(userID)
+	if err := a.resumeSession(user); err != nil {
+		return model.AnswerChatResponse{}, err
+	}
+
+	entry, err := a.model.GetChatTexts(userID)
 	if err != nil {
 		return model.AnswerChatResponse{}, fmt.Errorf("failed to get chat: %v", err)
 	}
 
-	audioReader := bytes.NewReader(audioData)
-	transcript, err := a.oaiAPI.Transcribe(apiKey, audioReader, "audio.wav")
+	p, _, err = a.applyBudget(userID, p)
 	if err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to transcribe audio: %v", err)
+		return model.AnswerChatResponse{}, err
 	}
 
-	if transcript.Text == "" {
-		return model.AnswerChatResponse{}, fmt.Errorf("cannot complete audio transcription: no transcript")
+	ctx, endTurn := a.beginTurn(userID)
+	defer endTurn()
+
+	transcript, transcriber, err := a.transcribe(ctx, p, userID, user.Language, audioData)
+	if err != nil {
+		return model.AnswerChatResponse{}, stageError(ctx, "transcribe audio", err)
 	}
 
-	if _, err := a.model.CreateChat(userID, string(oaiModel.ROLE_USER), transcript.Text, base64.StdEncoding.EncodeToString(audioData)); err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to create chat: %v", err)
+	a.recordTranscriptUsage(userID, transcriber, transcript)
+
+	if transcript.Text == "" {
+		return model.AnswerChatResponse{}, fmt.Errorf("cannot complete audio transcription: no transcript")
 	}
 
 	chatHistory := append(entry, model.Entry{
@@ -170,35 +367,16 @@ func (a *App) AnswerChat(userID, userSecret string, audioData []byte) (model.Ans
 
 	chatMessages := entryToChatMessage(chatHistory)
 
-	chatCompletion, err := a.oaiAPI.Chat(apiKey, chatMessages)
+	answer, answerAudio, err := a.reply(ctx, p, user, chatMessages)
 	if err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to get chat completion: %v", err)
-	}
-
-	if len(chatCompletion.Choices) == 0 {
-		return model.AnswerChatResponse{}, fmt.Errorf("cannot complete chat completion: no chat completion")
-	}
-
-	speechText := chatCompletion.Choices[0].Message.Content
-
-	var speech io.Reader
-	if a.oaiAPI.IsSpeechAvailable(user.Language) {
-		speech, _ = a.oaiAPI.Speechify(apiKey, sanitizeString(chatCompletion.Choices[0].Message.Content))
-	} else {
-		speech, _ = a.elAPI.Speechify(elKey, sanitizeString(chatCompletion.Choices[0].Message.Content))
+		return model.AnswerChatResponse{}, err
 	}
 
-	var speechBase64 string
-	if speech != nil {
-		speechByte, err := io.ReadAll(speech)
-		if err != nil {
-			return model.AnswerChatResponse{}, fmt.Errorf("failed to read speech: %v", err)
-		}
-
-		speechBase64 = base64.StdEncoding.EncodeToString(speechByte)
-	}
-
-	if _, err := a.model.CreateChat(userID, string(oaiModel.ROLE_ASSISTANT), speechText, speechBase64); err != nil {
+	// the answer is only stored with its reply so a cancelled turn leaves the session as it was
+	if _, err := a.model.CreateChats(userID,
+		model.NewChat{Role: string(oaiModel.ROLE_USER), Text: transcript.Text, Audio: a.storeAudio(audioData, transcript.Seconds())},
+		model.NewChat{Role: string(oaiModel.ROLE_ASSISTANT), Text: answer.Text, Audio: a.storeAudio(answerAudio, 0)},
+	); err != nil {
 		return model.AnswerChatResponse{}, fmt.Errorf("failed to create chat: %v", err)
 	}
 
@@ -207,19 +385,16 @@ func (a *App) AnswerChat(userID, userSecret string, audioData []byte) (model.Ans
 		Prompt: model.Chat{
 			Text: transcript.Text,
 		},
-		Answer: model.Chat{
-			Text:  speechText,
-			Audio: speechBase64,
-		},
+		Answer: answer,
 	}
 
 	return response, nil
 }
 
 func (a *App) EndChat(userID, userSecret string) (model.AnswerChatResponse, error) {
-	apiKey, elKey, err := a.model.GetAPIKey()
+	p, err := a.getProviders()
 	if err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to get api key: %v", err)
+		return model.AnswerChatResponse{}, err
 	}
 
 	user, err := a.model.GetChatUser(userID)
@@ -231,11 +406,23 @@ func (a *App) EndChat(userID, userSecret string) (model.AnswerChatResponse, erro
 		return model.AnswerChatResponse{}, fmt.Errorf("invalid user secret")
 	}
 
-	entry, err := a.model.GetChatsByChatUserID(userID)
+	if err := a.resumeSession(user); err != nil {
+		return model.AnswerChatResponse{}, err
+	}
+
+	entry, err := a.model.GetChatTexts(userID)
 	if err != nil {
 		return model.AnswerChatResponse{}, fmt.Errorf("failed to get chat: %v", err)
 	}
 
+	p, _, err = a.applyBudget(userID, p)
+	if err != nil {
+		return model.AnswerChatResponse{}, err
+	}
+
+	ctx, endTurn := a.beginTurn(userID)
+	defer endTurn()
+
 	chatHistory := append(entry, model.Entry{
 		ChatUserID: userID,
 		Role:       string(oaiModel.ROLE_USER),
@@ -244,47 +431,177 @@ func (a *App) EndChat(userID, userSecret string) (model.AnswerChatResponse, erro
 
 	chatMessages := entryToChatMessage(chatHistory)
 
-	chatCompletion, err := a.oaiAPI.Chat(apiKey, chatMessages)
+	answer, answerAudio, err := a.reply(ctx, p, user, chatMessages)
 	if err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to get chat completion: %v", err)
+		return model.AnswerChatResponse{}, err
 	}
 
-	if len(chatCompletion.Choices) == 0 {
-		return model.AnswerChatResponse{}, fmt.Errorf("cannot complete chat completion: no chat completion")
+	if _, err := a.model.CreateChat(userID, string(oaiModel.ROLE_ASSISTANT), answer.Text, a.storeAudio(answerAudio, 0)); err != nil {
+		return model.AnswerChatResponse{}, fmt.Errorf("failed to create chat: %v", err)
 	}
 
-	speechText := chatCompletion.Choices[0].Message.Content
+	if err := a.model.UpdateChatUserStatus(userID, model.SESSION_ENDED); err != nil {
+		return model.AnswerChatResponse{}, fmt.Errorf("failed to end chat: %v", err)
+	}
 
-	var speech io.Reader
-	if a.oaiAPI.IsSpeechAvailable(user.Language) {
-		speech, _ = a.oaiAPI.Speechify(apiKey, sanitizeString(chatCompletion.Choices[0].Message.Content))
-	} else {
-		speech, _ = a.elAPI.Speechify(elKey, sanitizeString(chatCompletion.Choices[0].Message.Content))
+	response := model.AnswerChatResponse{
+		Language: language.GetCode(user.Language),
+		Answer:   answer,
 	}
 
-	var speechBase64 string
-	if speech != nil {
-		speechByte, err := io.ReadAll(speech)
-		if err != nil {
-			return model.AnswerChatResponse{}, fmt.Errorf("failed to read speech: %v", err)
-		}
+	return response, nil
+}
 
-		speechBase64 = base64.StdEncoding.EncodeToString(speechByte)
+// CancelTurn aborts the transcription, completion or speech still running for the session,
+// nothing of the cancelled turn is stored
+func (a *App) CancelTurn(userID, userSecret string) error {
+	user, err := a.model.GetChatUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
 	}
 
-	if _, err := a.model.CreateChat(userID, string(oaiModel.ROLE_ASSISTANT), speechText, speechBase64); err != nil {
-		return model.AnswerChatResponse{}, fmt.Errorf("failed to create chat: %v", err)
+	if err := compareHash(userSecret, user.Secret); err != nil {
+		return fmt.Errorf("invalid user secret")
 	}
 
-	response := model.AnswerChatResponse{
-		Language: language.GetCode(user.Language),
-		Answer: model.Chat{
-			Text:  speechText,
-			Audio: speechBase64,
-		},
+	a.cancelTurn(userID)
+
+	return nil
+}
+
+// ListSessions returns the interviews with the status: active, ended or abandoned, or all of them when it is empty,
+// the latest first
+func (a *App) ListSessions(status string, offset, limit int) ([]model.ChatUser, error) {
+	if status != "" && !isSessionStatus(status) {
+		return nil, fmt.Errorf("unsupported session status: %s", status)
 	}
 
-	return response, nil
+	if limit <= 0 || limit > maxSessionPage {
+		limit = maxSessionPage
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	sessions, err := a.model.GetChatUsers(model.SessionStatus(status), offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %v", err)
+	}
+
+	return sessions, nil
+}
+
+// GetHistory returns up to limit turns of the interview after the sequence, without their audio,
+// which is fetched with GetTurnAudio when it is played
+func (a *App) GetHistory(userID, userSecret string, afterSequence, limit int) ([]model.Entry, error) {
+	user, err := a.model.GetChatUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	if err := compareHash(userSecret, user.Secret); err != nil {
+		return nil, fmt.Errorf("invalid user secret")
+	}
+
+	if limit <= 0 || limit > maxHistoryPage {
+		limit = maxHistoryPage
+	}
+
+	entries, err := a.model.GetChatPage(userID, afterSequence, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	return entries, nil
+}
+
+// GetTurnAudio returns the base64 audio of a turn of the interview, empty when the turn has none
+func (a *App) GetTurnAudio(userID, userSecret, turnID string) (string, error) {
+	user, err := a.model.GetChatUser(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	if err := compareHash(userSecret, user.Secret); err != nil {
+		return "", fmt.Errorf("invalid user secret")
+	}
+
+	turn, err := a.model.GetChat(turnID)
+	if err != nil || turn.ChatUserID != userID {
+		return "", fmt.Errorf("failed to get turn: %s", turnID)
+	}
+
+	if turn.Audio.Key == "" {
+		return "", nil
+	}
+
+	audio, err := a.blobs.Get(turn.Audio.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(audio), nil
+}
+
+// GetSessionCost returns what the interview has consumed so far and its cost under the current prices
+func (a *App) GetSessionCost(userID, userSecret string) (model.Usage, error) {
+	user, err := a.model.GetChatUser(userID)
+	if err != nil {
+		return model.Usage{}, fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	if err := compareHash(userSecret, user.Secret); err != nil {
+		return model.Usage{}, fmt.Errorf("invalid user secret")
+	}
+
+	usage, err := a.model.GetSessionUsage(userID)
+	if err != nil {
+		return model.Usage{}, fmt.Errorf("failed to get usage: %v", err)
+	}
+
+	return usage, nil
+}
+
+// GetUsageSummary sums the usage of every interview in the period: day, week, month or all
+func (a *App) GetUsageSummary(period string) (model.UsageSummary, error) {
+	since, err := periodStart(period, time.Now())
+	if err != nil {
+		return model.UsageSummary{}, err
+	}
+
+	summary, err := a.model.GetUsageSince(since)
+	if err != nil {
+		return model.UsageSummary{}, fmt.Errorf("failed to get usage: %v", err)
+	}
+
+	summary.Period = period
+	if summary.Period == "" {
+		summary.Period = PERIOD_ALL
+	}
+
+	return summary, nil
+}
+
+func (a *App) GetPrices() ([]model.Price, error) {
+	prices, err := a.model.GetPrices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %v", err)
+	}
+
+	return prices, nil
+}
+
+func (a *App) UpdatePrice(price model.Price) error {
+	if err := validatePrice(price); err != nil {
+		return err
+	}
+
+	if err := a.model.UpdatePrice(price); err != nil {
+		return fmt.Errorf("failed to update price: %v", err)
+	}
+
+	return nil
 }
 
 func (a *App) ConfirmStartOver() (string, error) {
@@ -2,39 +2,96 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
 
+	"github.com/madeindra/interview-app/internal/audiocache"
+	"github.com/madeindra/interview-app/internal/blob"
 	"github.com/madeindra/interview-app/internal/database"
-	"github.com/madeindra/interview-app/internal/elevenlabs"
 	"github.com/madeindra/interview-app/internal/model"
-	"github.com/madeindra/interview-app/internal/openai"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx    context.Context
-	model  *model.Model
-	oaiAPI *openai.OpenAI
-	elAPI  *elevenlabs.ElevenLab
+	ctx   context.Context
+	model *model.Model
+
+	mu        sync.RWMutex
+	providers providers
+
+	turnMu sync.Mutex
+	turns  map[string]*turn
+
+	audio *audiocache.Cache
+	blobs *blob.Store
+
+	dbPath string
 }
 
-// NewApp creates a new App application struct
-func NewApp() *App {
-	return &App{}
+// NewApp creates a new App application struct, an empty database path is resolved at startup
+func NewApp(dbPath string) *App {
+	return &App{dbPath: dbPath}
 }
 
 // startup is called at application startup
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	db := database.New()
+	// the app can't run without its database, the error is shown before quitting
+	db, err := a.openDatabase()
+	if err != nil {
+		log.Default().Println("failed to open the database:", err)
+
+		runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+			Type:    runtime.ErrorDialog,
+			Title:   "Failed to start",
+			Message: err.Error(),
+		})
+		runtime.Quit(ctx)
+
+		return
+	}
+
 	a.model = model.New(db)
 
-	a.oaiAPI = openai.New()
-	a.elAPI = elevenlabs.New()
+	// speech is synthesized without the cache when it can't be created,
+	// its size limit is applied with the providers
+	if dir, err := audiocache.DefaultDir(); err != nil {
+		log.Default().Println("failed to find the audio cache directory:", err)
+	} else if a.audio, err = audiocache.New(dir, 0); err != nil {
+		log.Default().Println("failed to create the audio cache:", err)
+	}
+
+	if err := a.loadProviders(); err != nil {
+		log.Default().Println("failed to load providers:", err)
+	}
+
+	a.abandonSessions()
+
+	go a.collectAudio()
+}
+
+// openDatabase opens the database at the path of the flag or the environment, or in the user's config directory
+func (a *App) openDatabase() (*sql.DB, error) {
+	dbPath, err := database.Resolve(a.dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the audio of the interviews is kept next to the database, the migrations may move it there
+	a.blobs, err = blob.New(audioDirOf(dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the audio directory: %v", err)
+	}
+
+	return database.New(dbPath, a.blobs)
 }
 
 // domReady is called after front-end resources have been loaded
-func (a App) domReady(ctx context.Context) {
+func (a *App) domReady(ctx context.Context) {
 	// Add your action here
 }
 
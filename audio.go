@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/speech"
+)
+
+const (
+	// audioSuffix names the blob store of the recorded and synthesized audio after the database,
+	// so it never shares a folder with files of the user's own
+	audioSuffix = ".blobs"
+
+	// audioGrace spares new blobs from the collector while the chat referencing them is being saved
+	audioGrace = time.Hour
+)
+
+func audioDirOf(dbPath string) string {
+	return dbPath + audioSuffix
+}
+
+// storeAudio writes the audio to the blob store and returns the reference saved with the chat,
+// a failure is only logged so the chat is still saved, without its audio.
+// The duration is the one the provider reported, when it is zero it is read from wav, ogg or webm audio
+func (a *App) storeAudio(audio []byte, duration float64) model.AudioRef {
+	if len(audio) == 0 || a.blobs == nil {
+		return model.AudioRef{}
+	}
+
+	key, err := a.blobs.Put(audio)
+	if err != nil {
+		log.Default().Println("failed to store audio:", err)
+
+		return model.AudioRef{}
+	}
+
+	if duration <= 0 {
+		duration = speech.Duration(audio)
+	}
+
+	return model.AudioRef{
+		Key:      key,
+		Format:   speech.Format(audio),
+		Duration: duration,
+	}
+}
+
+// collectAudio removes the blobs no chat references anymore
+func (a *App) collectAudio() {
+	if a.blobs == nil {
+		return
+	}
+
+	keys, err := a.model.GetAudioKeys()
+	if err != nil {
+		log.Default().Println("failed to get the audio keys:", err)
+
+		return
+	}
+
+	removed, err := a.blobs.Sweep(keys, audioGrace)
+	if err != nil {
+		log.Default().Println("failed to collect orphaned audio:", err)
+	}
+
+	if removed > 0 {
+		log.Default().Printf("removed %d orphaned audio files", removed)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/budget"
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+// applyBudget checks the spending before a turn, warning the frontend past a soft limit and stopping
+// or degrading the turn past a hard one, it returns the providers to run the turn with and the warnings.
+// The warnings of an interview that hasn't started are only returned, as nothing listens to them yet
+func (a *App) applyBudget(userID string, p providers) (providers, []model.BudgetEvent, error) {
+	setting, err := a.model.GetSetting()
+	if err != nil {
+		return providers{}, nil, fmt.Errorf("failed to get setting: %v", err)
+	}
+
+	limits := budget.Limits{
+		Session: setting.BudgetSession,
+		Monthly: setting.BudgetMonthly,
+		SoftAt:  setting.BudgetSoftAt,
+	}
+
+	if limits.Session <= 0 && limits.Monthly <= 0 {
+		return p, nil, nil
+	}
+
+	// a session that hasn't started has nothing spent yet
+	var session float64
+	if userID != "" {
+		usage, err := a.model.GetSessionUsage(userID)
+		if err != nil {
+			return providers{}, nil, fmt.Errorf("failed to get usage: %v", err)
+		}
+
+		session = usage.Cost
+	}
+
+	since, err := periodStart(PERIOD_MONTH, time.Now())
+	if err != nil {
+		return providers{}, nil, err
+	}
+
+	monthly, err := a.model.GetUsageSince(since)
+	if err != nil {
+		return providers{}, nil, fmt.Errorf("failed to get usage: %v", err)
+	}
+
+	soft, hard := budget.Check(limits, session, monthly.Cost)
+
+	warnings := make([]model.BudgetEvent, 0, len(soft)+1)
+	for _, s := range soft {
+		warnings = append(warnings, model.BudgetEvent{
+			ID:    userID,
+			Scope: string(s.Scope),
+			Spent: s.Spent,
+			Limit: s.Limit,
+		})
+	}
+
+	if hard != nil {
+		degraded, ok := degrade(setting, p)
+		if !ok {
+			return providers{}, nil, hard
+		}
+
+		p = degraded
+		warnings = append(warnings, model.BudgetEvent{
+			ID:       userID,
+			Scope:    string(hard.Scope),
+			Spent:    hard.Spent,
+			Limit:    hard.Limit,
+			Exceeded: true,
+			Action:   setting.BudgetAction,
+		})
+	}
+
+	if userID != "" {
+		for _, warning := range warnings {
+			a.emit(EVENT_BUDGET_WARNING, warning)
+		}
+	}
+
+	return p, warnings, nil
+}
+
+// degrade swaps the chat model for the cheaper one or drops the speech, as set in the budget action.
+// The fallbacks would run their default models, which may cost more, so the cheaper model serves the chat alone
+func degrade(setting model.Setting, p providers) (providers, bool) {
+	switch budget.Action(setting.BudgetAction) {
+	case budget.ACTION_TEXT_ONLY:
+		p.speech, p.coverage = nil, nil
+
+		return p, true
+	case budget.ACTION_CHEAPER_MODEL:
+		if setting.BudgetChatModel == "" {
+			return p, false
+		}
+
+		setting.ChatModel = setting.BudgetChatModel
+		chat, err := newChatProvider(setting)
+		if err != nil {
+			log.Default().Println("failed to create the cheaper chat provider:", err)
+
+			return p, false
+		}
+
+		p.chat = chain[provider.ChatProvider]{{name: p.chat[0].name, provider: chat}}
+
+		return p, true
+	default:
+		return p, false
+	}
+}
+
+func validateBudget(setting model.Setting) error {
+	if setting.BudgetSession < 0 || setting.BudgetMonthly < 0 {
+		return fmt.Errorf("budgets can't be negative")
+	}
+
+	if setting.BudgetSoftAt < 0 || setting.BudgetSoftAt > 1 {
+		return fmt.Errorf("the soft limit must be between 0 and 1 of the budget")
+	}
+
+	if !budget.IsAction(setting.BudgetAction) {
+		return fmt.Errorf("unsupported budget action: %s", setting.BudgetAction)
+	}
+
+	if budget.Action(setting.BudgetAction) == budget.ACTION_CHEAPER_MODEL && setting.BudgetChatModel == "" {
+		return fmt.Errorf("a cheaper chat model is required to degrade to it")
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// EVENT_CHAT_DELTA carries each piece of the interviewer's reply while it is generated
+	EVENT_CHAT_DELTA = "chat:delta"
+
+	// EVENT_SPEECH_CHUNK carries the audio of each sentence of the reply, in order
+	EVENT_SPEECH_CHUNK = "speech:chunk"
+
+	// EVENT_BUDGET_WARNING is sent when a soft limit is crossed or a hard limit degrades the turn
+	EVENT_BUDGET_WARNING = "budget:warning"
+
+	// EVENT_PROVIDER_SERVED names the provider that served a stage of the turn, which may be a fallback
+	EVENT_PROVIDER_SERVED = "provider:served"
+)
+
+// emit sends an event to the frontend, it does nothing when the app runs without wails
+func (a *App) emit(name string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+
+	runtime.EventsEmit(a.ctx, name, data...)
+}
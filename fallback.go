@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/breaker"
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+const (
+	// breakerThreshold is how many failures in a row take a provider out of its chain
+	breakerThreshold = 3
+
+	// breakerCooldown is how long a failing provider is skipped before it is tried again
+	breakerCooldown = time.Minute
+)
+
+// link is a provider of a fallback chain, named for its breaker and the frontend
+type link[T any] struct {
+	name     provider.Name
+	provider T
+}
+
+// chain is the provider of a capability followed by its fallbacks, in the order they are tried
+type chain[T any] []link[T]
+
+// primary is the provider configured for the capability
+func (c chain[T]) primary() T {
+	var zero T
+	if len(c) == 0 {
+		return zero
+	}
+
+	return c[0].provider
+}
+
+func (c chain[T]) has(name provider.Name) bool {
+	for _, l := range c {
+		if l.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newChain builds the provider of the capability followed by its fallbacks,
+// which only keep the key and the provider-specific settings of the configured one
+func newChain[T any](setting model.Setting, capability provider.Capability, build func(model.Setting) (T, error)) (chain[T], error) {
+	primary, err := build(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	name, fallbacks := capabilityProviders(setting, capability)
+	c := chain[T]{{name: name, provider: primary}}
+
+	for _, fallback := range fallbacks {
+		p, err := build(fallbackSetting(setting, capability, provider.Name(fallback)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the %s fallback: %w", capability, err)
+		}
+
+		c = append(c, link[T]{name: provider.Name(fallback), provider: p})
+	}
+
+	return c, nil
+}
+
+// capabilityProviders returns the provider configured for the capability and its fallbacks
+func capabilityProviders(setting model.Setting, capability provider.Capability) (provider.Name, []string) {
+	switch capability {
+	case provider.CAPABILITY_CHAT:
+		return provider.Name(setting.ChatProvider), setting.ChatFallbacks
+	case provider.CAPABILITY_TRANSCRIPT:
+		return provider.Name(setting.TranscriptProvider), setting.TranscriptFallbacks
+	case provider.CAPABILITY_SPEECH:
+		return provider.Name(setting.SpeechProvider), setting.SpeechFallbacks
+	default:
+		return "", nil
+	}
+}
+
+// fallbackSetting serves the capability with another provider at its default server, model and voice,
+// the temperature validated for the configured provider is capped to the range of the fallback
+func fallbackSetting(setting model.Setting, capability provider.Capability, name provider.Name) model.Setting {
+	switch capability {
+	case provider.CAPABILITY_CHAT:
+		setting.ChatProvider = string(name)
+		setting.ChatBaseURL, setting.ChatModel, setting.ChatNoAuth = "", "", false
+
+		if t, limit := setting.ChatTemperature, maxTemperature(name); t != nil && *t > limit {
+			setting.ChatTemperature = &limit
+		}
+	case provider.CAPABILITY_TRANSCRIPT:
+		setting.TranscriptProvider = string(name)
+		setting.TranscriptBaseURL, setting.TranscriptModel, setting.TranscriptNoAuth = "", "", false
+	case provider.CAPABILITY_SPEECH:
+		setting.SpeechProvider = string(name)
+		setting.SpeechBaseURL, setting.SpeechModel, setting.SpeechNoAuth, setting.SpeechVoice = "", "", false, ""
+	}
+
+	return setting
+}
+
+// partialError stops the chain when a provider failed after part of its output reached the candidate
+type partialError struct {
+	err error
+}
+
+func (e *partialError) Error() string {
+	return e.err.Error()
+}
+
+func (e *partialError) Unwrap() error {
+	return e.err
+}
+
+// tryChain calls the providers of the chain in order until one succeeds, skipping those whose breaker is open,
+// every provider is tried when all the breakers are open rather than failing without a try.
+// Each call gets its own timeout, so a provider that runs out of time fails over like any other error.
+// It returns the provider that served the call, or the error of the first one that failed
+func tryChain[T, R any](ctx context.Context, breakers *breaker.Group, capability provider.Capability, c chain[T], timeout time.Duration, call func(context.Context, T) (R, error)) (R, link[T], error) {
+	var zero R
+
+	allowed := make(chain[T], 0, len(c))
+	for _, l := range c {
+		if breakers.Get(breakerKey(capability, l.name)).Allow() {
+			allowed = append(allowed, l)
+		}
+	}
+
+	if len(allowed) == 0 {
+		allowed = c
+	}
+
+	var firstErr error
+	for _, l := range allowed {
+		b := breakers.Get(breakerKey(capability, l.name))
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		resp, err := call(attemptCtx, l.provider)
+		cancel()
+
+		if err == nil {
+			b.Success()
+
+			return resp, l, nil
+		}
+
+		// a cancelled turn says nothing about the provider
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return zero, l, err
+		}
+
+		b.Failure()
+
+		var partial *partialError
+		if errors.As(err, &partial) {
+			return zero, l, partial.err
+		}
+
+		log.Default().Printf("failed to serve %s with %s: %v", capability, l.name, err)
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return zero, link[T]{}, firstErr
+}
+
+func breakerKey(capability provider.Capability, name provider.Name) string {
+	return fmt.Sprintf("%s:%s", capability, name)
+}
+
+// emitServed tells the frontend which provider served the stage of the turn and whether it was a fallback
+func (a *App) emitServed(userID string, capability provider.Capability, primary, served provider.Name) {
+	a.emit(EVENT_PROVIDER_SERVED, model.ProviderEvent{
+		ID:         userID,
+		Capability: string(capability),
+		Provider:   string(served),
+		Fallback:   served != primary,
+	})
+}
+
+// speechChain speaks every text with the first synthesizer of the chain that succeeds,
+// onServe is called whenever the synthesizer serving the speech changes
+type speechChain struct {
+	links    chain[provider.SpeechSynthesizer]
+	breakers *breaker.Group
+	onServe  func(provider.Name)
+
+	mu     sync.Mutex
+	served provider.Name
+}
+
+func (s *speechChain) Speechify(ctx context.Context, text string) (io.ReadCloser, error) {
+	audio, served, err := tryChain(ctx, s.breakers, provider.CAPABILITY_SPEECH, s.links, speechTimeout, func(ctx context.Context, synthesizer provider.SpeechSynthesizer) (io.ReadCloser, error) {
+		body, err := synthesizer.Speechify(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		// read within the call, as its timeout ends with it
+		audio, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(bytes.NewReader(audio)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	changed := s.served != served.name
+	s.served = served.name
+	s.mu.Unlock()
+
+	if changed && s.onServe != nil {
+		s.onServe(served.name)
+	}
+
+	return audio, nil
+}
+
+// IsSpeechAvailable is true as the chain only holds synthesizers that speak the language
+func (s *speechChain) IsSpeechAvailable(lang string) bool {
+	return true
+}
+
+// MaxInputLength is the shortest limit of the chain so any of them can speak every part
+func (s *speechChain) MaxInputLength() int {
+	limit := 0
+	for _, l := range s.links {
+		if n := l.provider.MaxInputLength(); limit == 0 || (n > 0 && n < limit) {
+			limit = n
+		}
+	}
+
+	return limit
+}
+
+// speechFor chains the synthesizers that can speak the language, each with the voice of the session
+// and through the speech cache, falling back to the coverage only when none of the chain can, nil when none can
+func (a *App) speechFor(p providers, userID, lang string, voice model.VoiceOptions) provider.SpeechSynthesizer {
+	links := a.speakers(p, p.speech, userID, lang, voice)
+	if len(links) == 0 {
+		links = a.speakers(p, p.coverage, userID, lang, voice)
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	return &speechChain{
+		links:    links,
+		breakers: p.breakers,
+		onServe: func(served provider.Name) {
+			a.emitServed(userID, provider.CAPABILITY_SPEECH, links[0].name, served)
+		},
+	}
+}
+
+// speakers keeps the synthesizers of the chain that can speak the language, metered and with the voice of the session
+func (a *App) speakers(p providers, c chain[provider.SpeechSynthesizer], userID, lang string, voice model.VoiceOptions) chain[provider.SpeechSynthesizer] {
+	var links chain[provider.SpeechSynthesizer]
+	for _, l := range c {
+		if l.provider.IsSpeechAvailable(lang) {
+			links = append(links, link[provider.SpeechSynthesizer]{
+				name:     l.name,
+				provider: a.meteredSpeech(p, sessionSpeech(l.provider, voice), userID),
+			})
+		}
+	}
+
+	return links
+}
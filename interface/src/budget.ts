@@ -0,0 +1,18 @@
+export interface BudgetWarning {
+  scope: string;
+  spent: number;
+  limit: number;
+  exceeded: boolean;
+  action?: string;
+}
+
+export const budgetMessage = (warning: BudgetWarning) => {
+  const spending = `$${warning.spent.toFixed(2)} of the $${warning.limit.toFixed(2)} ${warning.scope} budget`;
+  if (!warning.exceeded) {
+    return `You have spent ${spending}.`;
+  } else if (warning.action === 'text_only') {
+    return `You have spent ${spending}, the interviewer will reply in text only.`;
+  }
+
+  return `You have spent ${spending}, the interviewer now uses a cheaper model.`;
+};
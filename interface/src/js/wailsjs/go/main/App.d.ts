@@ -6,12 +6,42 @@ export function AnswerChat(arg1:string,arg2:string,arg3:Array<number>):Promise<m
 
 export function AreKeyExist():Promise<boolean>;
 
+export function CancelTurn(arg1:string,arg2:string):Promise<void>;
+
 export function ConfirmStartOver():Promise<string>;
 
 export function EndChat(arg1:string,arg2:string):Promise<model.AnswerChatResponse>;
 
-export function StartChat(arg1:string,arg2:Array<string>,arg3:string):Promise<model.StartChatResponse>;
+export function GetHistory(arg1:string,arg2:string,arg3:number,arg4:number):Promise<Array<model.Entry>>;
+
+export function GetPrices():Promise<Array<model.Price>>;
+
+export function GetSessionCost(arg1:string,arg2:string):Promise<model.Usage>;
+
+export function GetSettings():Promise<model.Setting>;
+
+export function GetTurnAudio(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function GetUsageSummary(arg1:string):Promise<model.UsageSummary>;
+
+export function ListModels(arg1:string):Promise<Array<string>>;
+
+export function ListSessions(arg1:string,arg2:number,arg3:number):Promise<Array<model.ChatUser>>;
+
+export function ListVoices():Promise<Array<model.Voice>>;
+
+export function PreviewVoice(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function StartChat(arg1:string,arg2:Array<string>,arg3:string,arg4:model.VoiceOptions):Promise<model.StartChatResponse>;
 
 export function Status():Promise<model.StatusResponse>;
 
 export function UpdateAPIKeys(arg1:string,arg2:string):Promise<void>;
+
+export function UpdateAnthropicKey(arg1:string):Promise<void>;
+
+export function UpdateAzureKey(arg1:string):Promise<void>;
+
+export function UpdatePrice(arg1:model.Price):Promise<void>;
+
+export function UpdateSettings(arg1:model.Setting):Promise<void>;
\ No newline at end of file
@@ -10,6 +10,10 @@ export function AreKeyExist() {
   return window['go']['main']['App']['AreKeyExist']();
 }
 
+export function CancelTurn(arg1, arg2) {
+  return window['go']['main']['App']['CancelTurn'](arg1, arg2);
+}
+
 export function ConfirmStartOver() {
   return window['go']['main']['App']['ConfirmStartOver']();
 }
@@ -18,8 +22,48 @@ export function EndChat(arg1, arg2) {
   return window['go']['main']['App']['EndChat'](arg1, arg2);
 }
 
-export function StartChat(arg1, arg2, arg3) {
-  return window['go']['main']['App']['StartChat'](arg1, arg2, arg3);
+export function GetHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetHistory'](arg1, arg2, arg3, arg4);
+}
+
+export function GetPrices() {
+  return window['go']['main']['App']['GetPrices']();
+}
+
+export function GetSessionCost(arg1, arg2) {
+  return window['go']['main']['App']['GetSessionCost'](arg1, arg2);
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function GetTurnAudio(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetTurnAudio'](arg1, arg2, arg3);
+}
+
+export function GetUsageSummary(arg1) {
+  return window['go']['main']['App']['GetUsageSummary'](arg1);
+}
+
+export function ListModels(arg1) {
+  return window['go']['main']['App']['ListModels'](arg1);
+}
+
+export function ListSessions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ListSessions'](arg1, arg2, arg3);
+}
+
+export function ListVoices() {
+  return window['go']['main']['App']['ListVoices']();
+}
+
+export function PreviewVoice(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewVoice'](arg1, arg2, arg3);
+}
+
+export function StartChat(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['StartChat'](arg1, arg2, arg3, arg4);
 }
 
 export function Status() {
@@ -29,3 +73,19 @@ export function Status() {
 export function UpdateAPIKeys(arg1, arg2) {
   return window['go']['main']['App']['UpdateAPIKeys'](arg1, arg2);
 }
+
+export function UpdateAnthropicKey(arg1) {
+  return window['go']['main']['App']['UpdateAnthropicKey'](arg1);
+}
+
+export function UpdateAzureKey(arg1) {
+  return window['go']['main']['App']['UpdateAzureKey'](arg1);
+}
+
+export function UpdatePrice(arg1) {
+  return window['go']['main']['App']['UpdatePrice'](arg1);
+}
+
+export function UpdateSettings(arg1) {
+  return window['go']['main']['App']['UpdateSettings'](arg1);
+}
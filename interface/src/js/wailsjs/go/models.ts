@@ -3,6 +3,7 @@ export namespace model {
 	export class Chat {
 	    text: string;
 	    audio: string;
+	    speechError?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Chat(source);
@@ -12,6 +13,7 @@ export namespace model {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.text = source["text"];
 	        this.audio = source["audio"];
+	        this.speechError = source["speechError"];
 	    }
 	}
 	export class AnswerChatResponse {
@@ -49,12 +51,36 @@ export namespace model {
 		}
 	}
 	
+	export class BudgetEvent {
+	    id: string;
+	    scope: string;
+	    spent: number;
+	    limit: number;
+	    exceeded: boolean;
+	    action?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.scope = source["scope"];
+	        this.spent = source["spent"];
+	        this.limit = source["limit"];
+	        this.exceeded = source["exceeded"];
+	        this.action = source["action"];
+	    }
+	}
 	export class StartChatResponse {
 	    id: string;
 	    secret: string;
 	    language: string;
+	    warnings?: BudgetEvent[];
 	    text: string;
 	    audio: string;
+	    speechError?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new StartChatResponse(source);
@@ -65,10 +91,31 @@ export namespace model {
 	        this.id = source["id"];
 	        this.secret = source["secret"];
 	        this.language = source["language"];
+	        this.warnings = this.convertValues(source["warnings"], BudgetEvent);
 	        this.text = source["text"];
 	        this.audio = source["audio"];
+	        this.speechError = source["speechError"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	
 	export class StatusResponse {
 	    server: boolean;
 	    key: boolean;
@@ -87,6 +134,302 @@ export namespace model {
 	        this.apiStatus = source["apiStatus"];
 	    }
 	}
+	export class Setting {
+	    chatProvider: string;
+	    chatBaseUrl: string;
+	    chatModel: string;
+	    chatNoAuth: boolean;
+	    chatTemperature?: number;
+	    chatMaxTokens?: number;
+	    chatTopP?: number;
+	    transcriptProvider: string;
+	    transcriptBaseUrl: string;
+	    transcriptModel: string;
+	    transcriptNoAuth: boolean;
+	    speechProvider: string;
+	    speechBaseUrl: string;
+	    speechModel: string;
+	    speechNoAuth: boolean;
+	    speechVoice: string;
+	    elevenlabsStability: number;
+	    elevenlabsSimilarity: number;
+	    whisperBinary: string;
+	    whisperModel: string;
+	    ffmpegBinary: string;
+	    piperBinary: string;
+	    piperModel: string;
+	    maxRetries: number;
+	    budgetSession: number;
+	    budgetMonthly: number;
+	    budgetSoftAt: number;
+	    budgetAction: string;
+	    budgetChatModel: string;
+	    azureApiVersion: string;
+	    speechCache: boolean;
+	    speechCacheSize: number;
+	    chatFallbacks: string[];
+	    transcriptFallbacks: string[];
+	    speechFallbacks: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Setting(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.chatProvider = source["chatProvider"];
+	        this.chatBaseUrl = source["chatBaseUrl"];
+	        this.chatModel = source["chatModel"];
+	        this.chatNoAuth = source["chatNoAuth"];
+	        this.chatTemperature = source["chatTemperature"];
+	        this.chatMaxTokens = source["chatMaxTokens"];
+	        this.chatTopP = source["chatTopP"];
+	        this.transcriptProvider = source["transcriptProvider"];
+	        this.transcriptBaseUrl = source["transcriptBaseUrl"];
+	        this.transcriptModel = source["transcriptModel"];
+	        this.transcriptNoAuth = source["transcriptNoAuth"];
+	        this.speechProvider = source["speechProvider"];
+	        this.speechBaseUrl = source["speechBaseUrl"];
+	        this.speechModel = source["speechModel"];
+	        this.speechNoAuth = source["speechNoAuth"];
+	        this.speechVoice = source["speechVoice"];
+	        this.elevenlabsStability = source["elevenlabsStability"];
+	        this.elevenlabsSimilarity = source["elevenlabsSimilarity"];
+	        this.whisperBinary = source["whisperBinary"];
+	        this.whisperModel = source["whisperModel"];
+	        this.ffmpegBinary = source["ffmpegBinary"];
+	        this.piperBinary = source["piperBinary"];
+	        this.piperModel = source["piperModel"];
+	        this.maxRetries = source["maxRetries"];
+	        this.budgetSession = source["budgetSession"];
+	        this.budgetMonthly = source["budgetMonthly"];
+	        this.budgetSoftAt = source["budgetSoftAt"];
+	        this.budgetAction = source["budgetAction"];
+	        this.budgetChatModel = source["budgetChatModel"];
+	        this.azureApiVersion = source["azureApiVersion"];
+	        this.speechCache = source["speechCache"];
+	        this.speechCacheSize = source["speechCacheSize"];
+	        this.chatFallbacks = source["chatFallbacks"];
+	        this.transcriptFallbacks = source["transcriptFallbacks"];
+	        this.speechFallbacks = source["speechFallbacks"];
+	    }
+	}
+	export class Price {
+	    model: string;
+	    inputPrice: number;
+	    outputPrice: number;
+	    audioPrice: number;
+	    characterPrice: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Price(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model = source["model"];
+	        this.inputPrice = source["inputPrice"];
+	        this.outputPrice = source["outputPrice"];
+	        this.audioPrice = source["audioPrice"];
+	        this.characterPrice = source["characterPrice"];
+	    }
+	}
+	export class Usage {
+	    inputTokens: number;
+	    outputTokens: number;
+	    audioSeconds: number;
+	    characters: number;
+	    cost: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Usage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.inputTokens = source["inputTokens"];
+	        this.outputTokens = source["outputTokens"];
+	        this.audioSeconds = source["audioSeconds"];
+	        this.characters = source["characters"];
+	        this.cost = source["cost"];
+	    }
+	}
+	export class UsageSummary {
+	    period: string;
+	    sessions: number;
+	    inputTokens: number;
+	    outputTokens: number;
+	    audioSeconds: number;
+	    characters: number;
+	    cost: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new UsageSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.sessions = source["sessions"];
+	        this.inputTokens = source["inputTokens"];
+	        this.outputTokens = source["outputTokens"];
+	        this.audioSeconds = source["audioSeconds"];
+	        this.characters = source["characters"];
+	        this.cost = source["cost"];
+	    }
+	}
+	export class Voice {
+	    id: string;
+	    name: string;
+	    category: string;
+	    labels: {[key: string]: string};
+	    previewUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Voice(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.category = source["category"];
+	        this.labels = source["labels"];
+	        this.previewUrl = source["previewUrl"];
+	    }
+	}
+	export class VoiceOptions {
+	    voice: string;
+	    stability?: number;
+	    similarity?: number;
+	    style?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VoiceOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.voice = source["voice"];
+	        this.stability = source["stability"];
+	        this.similarity = source["similarity"];
+	        this.style = source["style"];
+	    }
+	}
+	export class AudioRef {
+	    key: string;
+	    format: string;
+	    duration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AudioRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.format = source["format"];
+	        this.duration = source["duration"];
+	    }
+	}
+	export class Entry {
+	    id: string;
+	    chat_user_id: string;
+	    sequence: number;
+	    role: string;
+	    text: string;
+	    audio: AudioRef;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.chat_user_id = source["chat_user_id"];
+	        this.sequence = source["sequence"];
+	        this.role = source["role"];
+	        this.text = source["text"];
+	        this.audio = this.convertValues(source["audio"], AudioRef);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ChatUser {
+	    id: string;
+	    language: string;
+	    voice: VoiceOptions;
+	    role: string;
+	    skills: string[];
+	    chatProvider: string;
+	    chatModel: string;
+	    transcriptProvider: string;
+	    transcriptModel: string;
+	    speechProvider: string;
+	    speechModel: string;
+	    createdAt?: any;
+	    updatedAt?: any;
+	    endedAt?: any;
+	    status: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChatUser(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.language = source["language"];
+	        this.voice = this.convertValues(source["voice"], VoiceOptions);
+	        this.role = source["role"];
+	        this.skills = source["skills"];
+	        this.chatProvider = source["chatProvider"];
+	        this.chatModel = source["chatModel"];
+	        this.transcriptProvider = source["transcriptProvider"];
+	        this.transcriptModel = source["transcriptModel"];
+	        this.speechProvider = source["speechProvider"];
+	        this.speechModel = source["speechModel"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.endedAt = this.convertValues(source["endedAt"], null);
+	        this.status = source["status"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -3,7 +3,9 @@ import { useNavigate } from 'react-router-dom';
 import AnimatedText from './AnimatedText';
 import Navbar from './Navbar';
 import { Message, useInterviewStore } from '../store';
-import { AnswerChat, EndChat } from '../js/wailsjs/go/main/App';
+import { BudgetWarning, budgetMessage } from '../budget';
+import { AnswerChat, CancelTurn, EndChat } from '../js/wailsjs/go/main/App';
+import { EventsOn } from '../js/wailsjs/runtime/runtime';
 
 interface ChatScreenProps {
   setError: (error: string | null) => void;
@@ -14,10 +16,13 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
   const [isRecording, setIsRecording] = useState(false);
   const [isProcessing, setIsProcessing] = useState(false);
   const [hasStarted, setHasStarted] = useState(false);
+  const [streamingText, setStreamingText] = useState('');
 
   const navigate = useNavigate();
 
   const audioRef = useRef<HTMLAudioElement | null>(null);
+  const audioQueueRef = useRef<Array<string>>([]);
+  const hasChunksRef = useRef(false);
   const mediaRecorderRef = useRef<MediaRecorder | null>(null);
   const chatContainerRef = useRef<HTMLDivElement>(null);
 
@@ -39,11 +44,46 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
     }
   }, [isIntroDone, initialAudio, initialText, language, setIsIntroDone]);
 
+  useEffect(() => {
+    return EventsOn('chat:delta', (event: { id: string; delta: string }) => {
+      if (event.id === interviewId) {
+        setStreamingText((text) => text + event.delta);
+      }
+    });
+  }, [interviewId]);
+
+  useEffect(() => {
+    return EventsOn('speech:chunk', (event: { id: string; index: number; audio: string }) => {
+      if (event.id === interviewId && event.audio) {
+        hasChunksRef.current = true;
+        queueAudio(event.audio);
+      }
+    });
+  }, [interviewId]);
+
+  useEffect(() => {
+    return EventsOn('budget:warning', (event: { id: string } & BudgetWarning) => {
+      if (event.id && event.id !== interviewId) {
+        return;
+      }
+
+      setError(budgetMessage(event));
+    });
+  }, [interviewId, setError]);
+
+  useEffect(() => {
+    return EventsOn('provider:served', (event: { id: string; capability: string; provider: string; fallback: boolean }) => {
+      if (event.id === interviewId && event.fallback) {
+        setError(`The ${event.capability} provider is unavailable, ${event.provider} is used instead.`);
+      }
+    });
+  }, [interviewId, setError]);
+
   useEffect(() => {
     if (chatContainerRef.current) {
       chatContainerRef.current.scrollTop = chatContainerRef.current.scrollHeight;
     }
-  }, [messages]);
+  }, [messages, streamingText]);
 
   const startRecording = async () => {
     try {
@@ -55,8 +95,10 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
         audioChunks.push(event.data);
       };
 
+      // the browser picks the container, usually webm or ogg, which the backend reads from the audio itself
+      const mimeType = mediaRecorderRef.current.mimeType;
       mediaRecorderRef.current.onstop = () => {
-        const audioBlob = new Blob(audioChunks, { type: 'audio/wav' });
+        const audioBlob = new Blob(audioChunks, { type: mimeType });
         sendAudioToServer(audioBlob);
       };
 
@@ -77,6 +119,7 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
 
   const sendAudioToServer = async (audioBlob: Blob) => {
     setIsProcessing(true);
+    hasChunksRef.current = false;
 
     try {
       const audioArray = new Uint8Array(await audioBlob.arrayBuffer());
@@ -88,18 +131,21 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
       addMessage(userMessage);
       addMessage(botMessage);
 
-      if (response?.answer?.audio) {
+      if (response?.answer?.audio && !hasChunksRef.current) {
         playAudio(response.answer.audio);
-      } else if (response?.answer?.text) {
+      } else if (response?.answer?.text && !hasChunksRef.current) {
         synthesizeSpeech(response.answer.text, language);
       }
 
       setHasStarted(true);
     } catch (error) {
       console.error('Error sending audio:', error);
-      setError('Failed to send your response. Please check your connection and try again.');
+      if (!String(error).includes('cancelled')) {
+        setError(`Failed to send your response: ${error}`);
+      }
     } finally {
       setIsProcessing(false);
+      setStreamingText('');
     }
   };
 
@@ -112,11 +158,38 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
     stopAudio();
 
     // Create a new audio element and play the audio
-    audioRef.current = new Audio(`data:audio/mp3;base64,${base64Audio}`);
+    audioRef.current = new Audio(audioSource(base64Audio));
+    audioRef.current.play();
+  };
+
+  const audioSource = (base64Audio: string) => {
+    // local voices return wav, which starts with RIFF
+    const type = base64Audio.startsWith('UklGR') ? 'audio/wav' : 'audio/mp3';
+    return `data:${type};base64,${base64Audio}`;
+  };
+
+  const queueAudio = (base64Audio: string) => {
+    audioQueueRef.current.push(base64Audio);
+
+    if (!audioRef.current || audioRef.current.ended || audioRef.current.paused) {
+      playNextAudio();
+    }
+  };
+
+  const playNextAudio = () => {
+    const next = audioQueueRef.current.shift();
+    if (!next) {
+      return
+    }
+
+    audioRef.current = new Audio(audioSource(next));
+    audioRef.current.onended = playNextAudio;
     audioRef.current.play();
   };
 
   const stopAudio = () => {
+    audioQueueRef.current = [];
+
     if (audioRef.current) {
       audioRef.current.pause();
       audioRef.current.currentTime = 0;
@@ -140,6 +213,7 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
 
   const endInterview = async () => {
     setIsProcessing(true);
+    hasChunksRef.current = false;
 
     try {
       const response = await EndChat(interviewId, interviewSecret)
@@ -147,18 +221,29 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
       const botMessage: Message = { text: response?.answer?.text ?? '', isUser: false, isAnimated: true };
       addMessage(botMessage);
 
-      if (response?.answer?.audio) {
+      if (response?.answer?.audio && !hasChunksRef.current) {
         playAudio(response.answer.audio);
-      } else if (response?.answer?.text) {
+      } else if (response?.answer?.text && !hasChunksRef.current) {
         synthesizeSpeech(response.answer.text, language);
       }
 
       setHasEnded(true);
     } catch (error) {
       console.error('Error ending interview:', error);
-      setError('Failed to end the interview. Please check your connection and try again.');
+      if (!String(error).includes('cancelled')) {
+        setError(`Failed to end the interview: ${error}`);
+      }
     } finally {
       setIsProcessing(false);
+      setStreamingText('');
+    }
+  };
+
+  const cancelTurn = async () => {
+    try {
+      await CancelTurn(interviewId, interviewSecret);
+    } catch (error) {
+      console.error('Error cancelling turn:', error);
     }
   };
 
@@ -198,6 +283,13 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
             </span>
           </div>
         ))}
+        {isProcessing && streamingText && (
+          <div className="mb-4 text-left">
+            <span className="inline-block p-3 rounded-2xl bg-[#2B2B3B] text-white">
+              {streamingText}
+            </span>
+          </div>
+        )}
       </div>
 
       <div className="flex justify-between items-center space-x-4 p-4 bg-[#1E1E2E]">
@@ -220,7 +312,15 @@ const ChatScreen: React.FC<ChatScreenProps> = ({ setError }) => {
                 : 'Start Recording'
           }
         </button>
-        {hasStarted && !hasEnded && (
+        {isProcessing && (
+          <button
+            onClick={cancelTurn}
+            className="w-3/12 p-4 rounded-xl font-bold text-lg bg-[#FF3E3E] text-white hover:bg-opacity-90 transition-all duration-300"
+          >
+            Cancel
+          </button>
+        )}
+        {hasStarted && !hasEnded && !isProcessing && (
           <button
             onClick={endInterview}
             disabled={isProcessing || isRecording || hasEnded}
@@ -1,26 +1,64 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useNavigate } from 'react-router-dom';
 import Navbar from './Navbar';
 import { useInterviewStore } from '../store';
-import { UpdateAPIKeys } from '../js/wailsjs/go/main/App';
+import { GetSettings, ListModels, UpdateAPIKeys, UpdateSettings } from '../js/wailsjs/go/main/App';
+import { model } from '../js/wailsjs/go/models';
 
 interface SettingScreenProps {
     setError: (error: string | null) => void;
 }
 
+type Capability = 'chat' | 'transcript' | 'speech';
+
+const modelFields: Array<{ capability: Capability; label: string; field: 'chatModel' | 'transcriptModel' | 'speechModel' }> = [
+    { capability: 'chat', label: 'Chat Model', field: 'chatModel' },
+    { capability: 'transcript', label: 'Transcription Model', field: 'transcriptModel' },
+    { capability: 'speech', label: 'Speech Model', field: 'speechModel' },
+];
+
 const SettingScreen: React.FC<SettingScreenProps> = ({ setError }) => {
     const { messages } = useInterviewStore();
 
     const [openaiKeyInput, setOpenaiKeyInput] = useState('');
     const [elevenlabsKeyInput, setElevenlabsKeyInput] = useState('');
+    const [setting, setSetting] = useState<model.Setting | null>(null);
+    const [models, setModels] = useState<Partial<Record<Capability, string[]>>>({});
+    const [modelsChanged, setModelsChanged] = useState(false);
 
     const navigate = useNavigate();
 
+    useEffect(() => {
+        GetSettings()
+            .then(setSetting)
+            .catch((error) => console.error('Error getting settings:', error));
+
+        // a provider that can't list its models keeps the model it is configured with
+        modelFields.forEach(({ capability }) => {
+            ListModels(capability)
+                .then((list) => setModels((current) => ({ ...current, [capability]: list })))
+                .catch((error) => console.error(`Error listing ${capability} models:`, error));
+        });
+    }, []);
+
+    const setModel = (field: 'chatModel' | 'transcriptModel' | 'speechModel', value: string) => {
+        if (setting) {
+            setSetting(model.Setting.createFrom({ ...setting, [field]: value }));
+            setModelsChanged(true);
+        }
+    };
+
     const handleSave = async (e: React.FormEvent) => {
         e.preventDefault();
 
         try {
-            await UpdateAPIKeys(openaiKeyInput, elevenlabsKeyInput);
+            if (openaiKeyInput || elevenlabsKeyInput) {
+                await UpdateAPIKeys(openaiKeyInput, elevenlabsKeyInput);
+            }
+
+            if (setting && modelsChanged) {
+                await UpdateSettings(setting);
+            }
 
             if (messages.length > 0) {
                 navigate('/chat');
@@ -28,7 +66,7 @@ const SettingScreen: React.FC<SettingScreenProps> = ({ setError }) => {
                 navigate('/');
             }
         } catch (error) {
-            setError('Failed to save settings. Please check your connection and try again.');
+            setError(`Failed to save settings: ${error}`);
         }
     };
 
@@ -77,6 +115,34 @@ const SettingScreen: React.FC<SettingScreenProps> = ({ setError }) => {
                                 className="w-full p-3 bg-[#3A3A4E] text-white border border-[#4A4A5E] rounded-lg focus:outline-none focus:ring-2 focus:ring-[#3E64FF]"
                             />
                         </div>
+                        {setting && modelFields.map(({ capability, label, field }) => {
+                            const list = models[capability];
+                            if (!list || list.length === 0) {
+                                return null;
+                            }
+
+                            // a saved model the provider doesn't list, such as an alias, stays selectable
+                            const options = setting[field] && !list.includes(setting[field]) ? [setting[field], ...list] : list;
+
+                            return (
+                                <div key={capability}>
+                                    <label htmlFor={`${capability}-model`} className="block mb-2 text-white font-semibold">
+                                        {label}
+                                    </label>
+                                    <select
+                                        id={`${capability}-model`}
+                                        value={setting[field]}
+                                        onChange={(e) => setModel(field, e.target.value)}
+                                        className="w-full p-3 bg-[#3A3A4E] text-white border border-[#4A4A5E] rounded-lg focus:outline-none focus:ring-2 focus:ring-[#3E64FF]"
+                                    >
+                                        <option value="">Provider default</option>
+                                        {options.map((name) => (
+                                            <option key={name} value={name}>{name}</option>
+                                        ))}
+                                    </select>
+                                </div>
+                            );
+                        })}
                         <button
                             type="submit"
                             className="w-full p-4 bg-[#3E64FF] text-white font-bold rounded-xl hover:bg-opacity-90 transition-all duration-300"
@@ -1,9 +1,11 @@
-import React, { useEffect } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useNavigate } from 'react-router-dom';
 
-import { AreKeyExist, StartChat } from '../js/wailsjs/go/main/App';
+import { AreKeyExist, ListVoices, StartChat } from '../js/wailsjs/go/main/App';
+import { model } from '../js/wailsjs/go/models';
 
 import { useInterviewStore } from '../store';
+import { budgetMessage } from '../budget';
 
 import Navbar from './Navbar';
 
@@ -17,11 +19,27 @@ const languageOptions = [
   { name: "Bahasa Indonesia", code: "id-ID" },
 ];
 
+type VoiceSetting = 'stability' | 'similarity' | 'style';
+
+const voiceSettings: Array<{ field: VoiceSetting; label: string }> = [
+  { field: 'stability', label: 'Stability' },
+  { field: 'similarity', label: 'Similarity' },
+  { field: 'style', label: 'Style' },
+];
+
 const StartScreen: React.FC<StartScreenProps> = ({ setError }) => {
   const { role, skills, language, messages, setHasEnded, setIsIntroDone, setMessages, setRole, setSkills, setLanguage, setInterviewId, setInterviewSecret, setInitialAudio, setInitialText } = useInterviewStore();
 
+  // the voices are only listed when elevenlabs is configured, unset options keep the settings
+  const [voices, setVoices] = useState<Array<model.Voice>>([]);
+  const [voiceOptions, setVoiceOptions] = useState(new model.VoiceOptions({ voice: '' }));
+
   const navigate = useNavigate();
 
+  const setVoiceOption = (field: 'voice' | VoiceSetting, value: string | number) => {
+    setVoiceOptions(model.VoiceOptions.createFrom({ ...voiceOptions, [field]: value }));
+  };
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     const skillsArray = skills.split(',').map(skill => skill.trim());
@@ -31,7 +49,7 @@ const StartScreen: React.FC<StartScreenProps> = ({ setError }) => {
     navigate('/processing');
 
     try {
-      const response = await StartChat(role, skillsArray, language);
+      const response = await StartChat(role, skillsArray, language, voiceOptions);
         
         setInterviewId(response?.id);
         setInterviewSecret(response?.secret);
@@ -41,6 +59,12 @@ const StartScreen: React.FC<StartScreenProps> = ({ setError }) => {
         setLanguage(response?.language);
 
         setMessages([{ text: response?.text, isUser: false, isAnimated: true }]);
+
+        // the most severe warning comes last
+        const warnings = response?.warnings ?? [];
+        if (warnings.length > 0) {
+          setError(budgetMessage(warnings[warnings.length - 1]));
+        }
         setIsIntroDone(false);
         setHasEnded(false);
 
@@ -73,6 +97,12 @@ useEffect(() => {
     checkAPIKeys();
 }, []);
 
+  useEffect(() => {
+    ListVoices()
+      .then((list) => setVoices(list ?? []))
+      .catch((error) => console.error('Error listing voices:', error));
+  }, []);
+
   return (
     <div className="flex flex-col h-screen bg-[#1E1E2E] text-white">
       {messages.length > 0 && (
@@ -124,6 +154,39 @@ useEffect(() => {
                 ))}
               </select>
             </div>
+            {voices.length > 0 && (
+              <div>
+                <label htmlFor="voice" className="block mb-2 text-white font-semibold">Interviewer Voice</label>
+                <select
+                  id="voice"
+                  value={voiceOptions.voice}
+                  onChange={(e) => setVoiceOption('voice', e.target.value)}
+                  className="w-full p-3 bg-[#3A3A4E] text-white border border-[#4A4A5E] rounded-lg focus:outline-none focus:ring-2 focus:ring-[#3E64FF]"
+                >
+                  <option value="">Default voice</option>
+                  {voices.map((voice) => (
+                    <option key={voice.id} value={voice.id}>{voice.name}</option>
+                  ))}
+                </select>
+              </div>
+            )}
+            {voices.length > 0 && voiceSettings.map(({ field, label }) => (
+              <div key={field}>
+                <label htmlFor={field} className="block mb-2 text-white font-semibold">
+                  {label}: {voiceOptions[field] === undefined ? 'default' : voiceOptions[field]?.toFixed(2)}
+                </label>
+                <input
+                  type="range"
+                  id={field}
+                  min={0}
+                  max={1}
+                  step={0.05}
+                  value={voiceOptions[field] ?? 0.5}
+                  onChange={(e) => setVoiceOption(field, Number(e.target.value))}
+                  className="w-full accent-[#3E64FF]"
+                />
+              </div>
+            ))}
             <button type="submit" className="w-full p-4 bg-[#3E64FF] text-white font-bold rounded-xl hover:bg-opacity-90 transition-all duration-300">
               Start Interview
             </button>
@@ -0,0 +1,101 @@
+package anthropic
+
+import (
+	"net/http"
+
+	"github.com/madeindra/interview-app/internal/httpclient"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+type Anthropic struct {
+	client      *http.Client
+	apiKey      string
+	baseURL     string
+	chatModel   string
+	maxTokens   int
+	temperature *float32
+	topP        *float32
+	noAuth      bool
+}
+
+type Option func(*Anthropic)
+
+const (
+	baseURL    = "https://api.anthropic.com/v1"
+	apiVersion = "2023-06-01"
+	chatModel  = "claude-3-5-haiku-latest"
+
+	// maxTokens is required by the messages api, an interviewer's reply is far shorter
+	maxTokens = 1024
+)
+
+func New(apiKey string, opts ...Option) *Anthropic {
+	c := &Anthropic{
+		client:    httpclient.Default,
+		apiKey:    apiKey,
+		baseURL:   baseURL,
+		chatModel: chatModel,
+		maxTokens: maxTokens,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithHTTPClient replaces the shared retrying client
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Anthropic) {
+		if client != nil {
+			c.client = client
+		}
+	}
+}
+
+// WithBaseURL overrides the api url, an empty url keeps the default
+func WithBaseURL(url string) Option {
+	return func(c *Anthropic) {
+		if url != "" {
+			c.baseURL = url
+		}
+	}
+}
+
+// WithChatModel overrides the chat model, an empty name keeps the default
+func WithChatModel(name string) Option {
+	return func(c *Anthropic) {
+		if name != "" {
+			c.chatModel = name
+		}
+	}
+}
+
+// WithGeneration sets the temperature, max tokens and top p of every message, nil keeps the default
+func WithGeneration(temperature *float32, maxTokens *int, topP *float32) Option {
+	return func(c *Anthropic) {
+		c.temperature = temperature
+		c.topP = topP
+
+		if maxTokens != nil {
+			c.maxTokens = *maxTokens
+		}
+	}
+}
+
+// WithNoAuth stops sending the x-api-key header, for proxies that hold the key themselves
+func WithNoAuth(noAuth bool) Option {
+	return func(c *Anthropic) {
+		c.noAuth = noAuth
+	}
+}
+
+// ModelName is the model serving the capability, which is only ever chat
+func (c *Anthropic) ModelName(capability provider.Capability) string {
+	if capability != provider.CAPABILITY_CHAT {
+		return ""
+	}
+
+	return c.chatModel
+}
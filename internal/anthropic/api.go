@@ -0,0 +1,197 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/madeindra/interview-app/internal/anthropic/model"
+	oaiModel "github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+func (c *Anthropic) IsKeyValid(ctx context.Context) (bool, error) {
+	url, err := url.JoinPath(c.baseURL, "models")
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// Models lists the ids of the models that can serve the capability, which is only ever chat
+func (c *Anthropic) Models(ctx context.Context, capability provider.Capability) ([]string, error) {
+	if capability != provider.CAPABILITY_CHAT {
+		return []string{}, nil
+	}
+
+	url, err := url.JoinPath(c.baseURL, "models")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"?limit=1000", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelsResp model.ModelListResponse
+	if err := unmarshalJSONResponse(resp, &modelsResp); err != nil {
+		return nil, err
+	}
+
+	models := make([]string, 0, len(modelsResp.Data))
+	for _, m := range modelsResp.Data {
+		models = append(models, m.ID)
+	}
+
+	sort.Strings(models)
+
+	return models, nil
+}
+
+func (c *Anthropic) Chat(ctx context.Context, messages []oaiModel.ChatMessage) (oaiModel.ChatResponse, error) {
+	req, err := c.newMessageRequest(ctx, messages, false)
+	if err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+
+	var messageResp model.MessageResponse
+	if err := unmarshalJSONResponse(resp, &messageResp); err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+
+	return toChatResponse(messageResp), nil
+}
+
+// ChatStream requests the message as server-sent events, calling onDelta for every text delta
+// and returning the assembled message once the stream is done
+func (c *Anthropic) ChatStream(ctx context.Context, messages []oaiModel.ChatMessage, onDelta func(string)) (oaiModel.ChatResponse, error) {
+	req, err := c.newMessageRequest(ctx, messages, true)
+	if err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+
+	respBody, err := getResponseBody(resp)
+	if err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+	defer respBody.Close()
+
+	var message model.MessageResponse
+	var content strings.Builder
+
+	err = readEvents(respBody, func(data []byte) error {
+		var event model.StreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case "message_start":
+			message = event.Message
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				return nil
+			}
+
+			content.WriteString(event.Delta.Text)
+			if onDelta != nil {
+				onDelta(event.Delta.Text)
+			}
+		case "message_delta":
+			message.StopReason = event.Delta.StopReason
+			message.Usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return &provider.Error{
+				Provider: provider.PROVIDER_ANTHROPIC,
+				Kind:     errorKind(event.Error, provider.ERROR_UNKNOWN),
+				Code:     event.Error.Type,
+				Message:  event.Error.Message,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return oaiModel.ChatResponse{}, err
+	}
+
+	message.Content = []model.ContentBlock{{Type: "text", Text: content.String()}}
+
+	return toChatResponse(message), nil
+}
+
+func (c *Anthropic) newMessageRequest(ctx context.Context, messages []oaiModel.ChatMessage, stream bool) (*http.Request, error) {
+	url, err := url.JoinPath(c.baseURL, "messages")
+	if err != nil {
+		return nil, err
+	}
+
+	system, turns := toMessages(messages)
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("no message to reply to")
+	}
+
+	messageReq := model.MessageRequest{
+		Model:       c.chatModel,
+		System:      system,
+		Messages:    turns,
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		TopP:        c.topP,
+		Stream:      stream,
+	}
+
+	body, err := json.Marshal(messageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
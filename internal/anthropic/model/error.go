@@ -0,0 +1,11 @@
+package model
+
+type ErrorResponse struct {
+	Type  string      `json:"type"`
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
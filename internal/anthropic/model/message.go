@@ -0,0 +1,57 @@
+package model
+
+type Role string
+
+const (
+	ROLE_USER      Role = "user"
+	ROLE_ASSISTANT Role = "assistant"
+)
+
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+}
+
+type MessageRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float32  `json:"temperature,omitempty"`
+	TopP        *float32  `json:"top_p,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+type MessageResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Role       Role           `json:"role"`
+	Content    []ContentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      Usage          `json:"usage"`
+}
+
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// StreamEvent is any of the server-sent events of a streamed message, only the fields of its type are set
+type StreamEvent struct {
+	Type    string          `json:"type"`
+	Message MessageResponse `json:"message"`
+	Delta   StreamDelta     `json:"delta"`
+	Usage   Usage           `json:"usage"`
+	Error   ErrorDetail     `json:"error"`
+}
+
+type StreamDelta struct {
+	Type       string `json:"type"`
+	Text       string `json:"text"`
+	StopReason string `json:"stop_reason"`
+}
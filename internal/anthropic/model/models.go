@@ -0,0 +1,12 @@
+package model
+
+type ModelListResponse struct {
+	Data    []Model `json:"data"`
+	HasMore bool    `json:"has_more"`
+}
+
+type Model struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	DisplayName string `json:"display_name"`
+}
@@ -0,0 +1,182 @@
+package anthropic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/madeindra/interview-app/internal/anthropic/model"
+	oaiModel "github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+// openingTurn stands in for the candidate when the history starts with the interviewer,
+// as the messages api requires the first turn to be the user's
+const openingTurn = "Hello, I am ready for the interview."
+
+func getResponseBody(resp *http.Response) (io.ReadCloser, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		return nil, decodeError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func unmarshalJSONResponse(resp *http.Response, v interface{}) error {
+	respBody, err := getResponseBody(resp)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+
+	return json.NewDecoder(respBody).Decode(v)
+}
+
+func (c *Anthropic) setHeaders(req *http.Request) {
+	req.Header.Set("anthropic-version", apiVersion)
+
+	if c.noAuth {
+		return
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+}
+
+// toMessages hoists the system prompts into the system field and merges consecutive turns of the same role,
+// the messages api rejects a history that doesn't alternate between user and assistant
+func toMessages(messages []oaiModel.ChatMessage) (string, []model.Message) {
+	var system []string
+	var turns []model.Message
+
+	for _, m := range messages {
+		if strings.TrimSpace(m.Content) == "" {
+			continue
+		}
+
+		role := model.ROLE_USER
+		switch m.Role {
+		case oaiModel.ROLE_SYSTEM:
+			system = append(system, m.Content)
+			continue
+		case oaiModel.ROLE_ASSISTANT:
+			role = model.ROLE_ASSISTANT
+		}
+
+		if n := len(turns); n > 0 && turns[n-1].Role == role {
+			turns[n-1].Content += "\n\n" + m.Content
+			continue
+		}
+
+		if len(turns) == 0 && role == model.ROLE_ASSISTANT {
+			turns = append(turns, model.Message{Role: model.ROLE_USER, Content: openingTurn})
+		}
+
+		turns = append(turns, model.Message{Role: role, Content: m.Content})
+	}
+
+	return strings.Join(system, "\n\n"), turns
+}
+
+// toChatResponse maps the message onto the openai response the rest of the app works with
+func toChatResponse(resp model.MessageResponse) oaiModel.ChatResponse {
+	var content strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	return oaiModel.ChatResponse{
+		Model: resp.Model,
+		Choices: []oaiModel.Choice{
+			{
+				Message: oaiModel.ChatMessage{
+					Role:    oaiModel.ROLE_ASSISTANT,
+					Content: content.String(),
+				},
+				FinishReason: finishReason(resp.StopReason),
+			},
+		},
+		Usage: oaiModel.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}
+}
+
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return "stop"
+	case "max_tokens":
+		return "length"
+	default:
+		return stopReason
+	}
+}
+
+// readEvents calls onData with the payload of every server-sent event until the stream ends
+func readEvents(body io.Reader, onData func([]byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		if err := onData([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:")))); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// decodeError turns the {"type": "error", "error": {...}} body into a provider error
+func decodeError(resp *http.Response) error {
+	apiErr := &provider.Error{
+		Provider:   provider.PROVIDER_ANTHROPIC,
+		Kind:       provider.KindFromStatus(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+	}
+
+	var errResp model.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		return apiErr
+	}
+
+	apiErr.Code = errResp.Error.Type
+	apiErr.Message = errResp.Error.Message
+	apiErr.Kind = errorKind(errResp.Error, apiErr.Kind)
+
+	return apiErr
+}
+
+func errorKind(detail model.ErrorDetail, fallback provider.ErrorKind) provider.ErrorKind {
+	switch detail.Type {
+	case "authentication_error", "permission_error":
+		return provider.ERROR_INVALID_KEY
+	case "not_found_error":
+		return provider.ERROR_MODEL_NOT_FOUND
+	case "request_too_large":
+		return provider.ERROR_CONTENT_TOO_LONG
+	case "rate_limit_error", "overloaded_error":
+		return provider.ERROR_RATE_LIMITED
+	case "billing_error":
+		return provider.ERROR_QUOTA_EXCEEDED
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,213 @@
+package audiocache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	appDir    = "interview-app"
+	extension = ".audio"
+)
+
+// Cache keeps synthesized audio on disk under a size limit, evicting the least recently used files first,
+// a nil cache stores nothing and finds nothing
+type Cache struct {
+	dir string
+
+	mu    sync.Mutex
+	size  int64
+	limit int64
+}
+
+// New creates the cache in dir, creating the directory when it doesn't exist, a limit of zero is unbounded
+func New(dir string, limit int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &Cache{dir: dir, limit: limit}
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		c.size += e.size
+	}
+
+	return c, nil
+}
+
+// DefaultDir is the audio directory under the user's cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, appDir, "audio"), nil
+}
+
+// Key hashes the parts into a file name, the parts are separated so "ab", "c" differs from "a", "bc"
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SetLimit changes the size limit in bytes and evicts what no longer fits
+func (c *Cache) SetLimit(limit int64) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.limit = limit
+
+	return c.evict()
+}
+
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	path := c.path(key)
+
+	audio, err := os.ReadFile(path)
+	if err != nil || len(audio) == 0 {
+		return nil, false
+	}
+
+	// the modification time orders the eviction
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return audio, true
+}
+
+// Put writes the audio to a temporary file first so a reader never sees half of it
+func (c *Cache) Put(key string, audio []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(audio); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	c.size += int64(len(audio)) - previous
+
+	return c.evict()
+}
+
+type entry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// evict removes the least recently used files until the cache fits its limit, c.mu must be held
+func (c *Cache) evict() error {
+	if c.limit <= 0 || c.size <= c.limit {
+		return nil
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	c.size = 0
+	for _, e := range entries {
+		c.size += e.size
+	}
+
+	for _, e := range entries {
+		if c.size <= c.limit {
+			break
+		}
+
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		c.size -= e.size
+	}
+
+	return nil
+}
+
+func (c *Cache) entries() ([]entry, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, 0, len(dirEntries))
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), extension) {
+			continue
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, entry{
+			path:    filepath.Join(c.dir, d.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	return entries, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+extension)
+}
@@ -0,0 +1,67 @@
+package audiocache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+// Synthesizer serves Speechify from the cache and stores the audio of every text it has to speak
+type Synthesizer struct {
+	provider.SpeechSynthesizer
+
+	cache    *Cache
+	describe string
+	onSpeak  func(text string)
+}
+
+// Wrap caches the speech of the synthesizer, onSpeak is called for every text that was actually synthesized,
+// a synthesizer that can't describe its voice is only metered since its audio can't be told apart
+func Wrap(synthesizer provider.SpeechSynthesizer, cache *Cache, onSpeak func(text string)) provider.SpeechSynthesizer {
+	describer, ok := synthesizer.(provider.SpeechDescriber)
+	if cache == nil || !ok {
+		return &Synthesizer{SpeechSynthesizer: synthesizer, onSpeak: onSpeak}
+	}
+
+	return &Synthesizer{
+		SpeechSynthesizer: synthesizer,
+		cache:             cache,
+		describe:          describer.DescribeSpeech(),
+		onSpeak:           onSpeak,
+	}
+}
+
+func (s *Synthesizer) Speechify(ctx context.Context, text string) (io.ReadCloser, error) {
+	key := Key(s.describe, text)
+	if s.cache != nil {
+		if audio, ok := s.cache.Get(key); ok {
+			return io.NopCloser(bytes.NewReader(audio)), nil
+		}
+	}
+
+	body, err := s.SpeechSynthesizer.Speechify(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	audio, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.onSpeak != nil {
+		s.onSpeak(text)
+	}
+
+	if s.cache != nil {
+		if err := s.cache.Put(key, audio); err != nil {
+			log.Default().Println("failed to cache speech:", err)
+		}
+	}
+
+	return io.NopCloser(bytes.NewReader(audio)), nil
+}
@@ -0,0 +1,163 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Store keeps files named by the SHA-256 of their content, so the same content is only stored once,
+// they are spread over subdirectories named by the first two characters of the key
+type Store struct {
+	dir string
+}
+
+// New creates the store in dir, creating the directory when it doesn't exist
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Key is the SHA-256 of the content in hex
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Put writes the content unless it is already stored and returns its key
+func (s *Store) Put(data []byte) (string, error) {
+	key := Key(data)
+	path := s.path(key)
+
+	if _, err := os.Stat(path); err == nil {
+		// refreshed so a blob referenced again isn't collected as an orphan
+		now := time.Now()
+		return key, os.Chtimes(path, now, now)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// Get reads the content of the key
+func (s *Store) Get(key string) ([]byte, error) {
+	if !isKey(key) {
+		return nil, fs.ErrNotExist
+	}
+
+	return os.ReadFile(s.path(key))
+}
+
+// Sweep removes the blobs that aren't kept, sparing those written within the grace period
+// as their reference may not be saved yet, and returns how many were removed.
+// Only the shard directories are walked and only blobs and their temporary files are removed,
+// anything else in the store is left alone
+func (s *Store) Sweep(keep map[string]struct{}, grace time.Duration) (int, error) {
+	cutoff := time.Now().Add(-grace)
+	removed := 0
+
+	shards, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() || !isShard(shard.Name()) {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(s.dir, shard.Name()))
+		if err != nil {
+			return removed, err
+		}
+
+		for _, file := range files {
+			name := file.Name()
+			if file.IsDir() || !isBlobFile(shard.Name(), name) {
+				continue
+			}
+
+			if _, ok := keep[name]; ok {
+				continue
+			}
+
+			info, err := file.Info()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+
+				return removed, err
+			}
+
+			if info.ModTime().After(cutoff) {
+				continue
+			}
+
+			if err := os.Remove(filepath.Join(s.dir, shard.Name(), name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return removed, err
+			}
+
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+func isKey(key string) bool {
+	return len(key) == sha256.Size*2 && isHex(key)
+}
+
+func isShard(name string) bool {
+	return len(name) == 2 && isHex(name)
+}
+
+// isBlobFile is true for a blob of the shard or the temporary file of an interrupted put, named <key>.*.tmp
+func isBlobFile(shard, name string) bool {
+	key, rest, found := strings.Cut(name, ".")
+	if !isKey(key) || key[:2] != shard {
+		return false
+	}
+
+	return !found || strings.HasSuffix(rest, ".tmp")
+}
+
+func isHex(s string) bool {
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
@@ -0,0 +1,87 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker skips a provider after repeated failures until the cool-down is over,
+// the first call after it is a trial that closes the breaker again or reopens it
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow is false while the breaker is open
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !time.Now().Before(b.openUntil)
+}
+
+// Success closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// Failure opens the breaker once the failures in a row reach the threshold,
+// a failed trial after the cool-down opens it again straight away
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// Group keeps a breaker per key, created on first use with the same threshold and cool-down
+type Group struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewGroup(threshold int, cooldown time.Duration) *Group {
+	return &Group{
+		threshold: threshold,
+		cooldown:  cooldown,
+		breakers:  map[string]*Breaker{},
+	}
+}
+
+func (g *Group) Get(key string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.breakers[key]
+	if !ok {
+		b = New(g.threshold, g.cooldown)
+		g.breakers[key] = b
+	}
+
+	return b
+}
@@ -0,0 +1,81 @@
+package budget
+
+import "fmt"
+
+type Scope string
+
+const (
+	SCOPE_SESSION Scope = "session"
+	SCOPE_MONTHLY Scope = "monthly"
+)
+
+// Action is what happens to a turn once a hard limit is reached
+type Action string
+
+const (
+	ACTION_STOP          Action = "stop"
+	ACTION_CHEAPER_MODEL Action = "cheaper_model"
+	ACTION_TEXT_ONLY     Action = "text_only"
+)
+
+// Limits are in USD, a zero limit is no limit, the soft limit is a fraction of the hard one
+type Limits struct {
+	Session float64
+	Monthly float64
+	SoftAt  float64
+}
+
+// Spend is what a scope has cost so far against its limit
+type Spend struct {
+	Scope Scope
+	Spent float64
+	Limit float64
+}
+
+// Error is returned once a hard limit is reached, use errors.As to inspect it
+type Error struct {
+	Spend
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("the %s budget of $%.2f is used up ($%.2f spent), please raise it in the settings", e.Scope, e.Limit, e.Spent)
+}
+
+// Check compares the spending of the session and the month with the limits,
+// it returns the scopes past their soft limit and the first scope past its hard limit
+func Check(limits Limits, session, monthly float64) ([]Spend, *Error) {
+	var soft []Spend
+	var hard *Error
+
+	for _, s := range []Spend{
+		{Scope: SCOPE_SESSION, Spent: session, Limit: limits.Session},
+		{Scope: SCOPE_MONTHLY, Spent: monthly, Limit: limits.Monthly},
+	} {
+		if s.Limit <= 0 {
+			continue
+		}
+
+		if s.Spent >= s.Limit {
+			if hard == nil {
+				hard = &Error{Spend: s}
+			}
+
+			continue
+		}
+
+		if limits.SoftAt > 0 && s.Spent >= s.Limit*limits.SoftAt {
+			soft = append(soft, s)
+		}
+	}
+
+	return soft, hard
+}
+
+func IsAction(action string) bool {
+	switch Action(action) {
+	case ACTION_STOP, ACTION_CHEAPER_MODEL, ACTION_TEXT_ONLY:
+		return true
+	default:
+		return false
+	}
+}
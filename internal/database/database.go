@@ -2,8 +2,14 @@ package database
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 
+	"github.com/madeindra/interview-app/internal/blob"
+	"github.com/madeindra/interview-app/internal/speech"
 	_ "modernc.org/sqlite"
 )
 
@@ -12,17 +18,59 @@ const (
 	CREATE TABLE IF NOT EXISTS settings (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		openai_key VARCHAR DEFAULT '',
-		elevenlabs_key VARCHAR DEFAULT ''
+		elevenlabs_key VARCHAR DEFAULT '',
+		chat_provider VARCHAR DEFAULT 'openai',
+		transcript_provider VARCHAR DEFAULT 'openai',
+		speech_provider VARCHAR DEFAULT 'openai',
+		chat_base_url VARCHAR DEFAULT '',
+		chat_model VARCHAR DEFAULT '',
+		chat_no_auth BOOLEAN DEFAULT 0,
+		transcript_base_url VARCHAR DEFAULT '',
+		transcript_model VARCHAR DEFAULT '',
+		transcript_no_auth BOOLEAN DEFAULT 0,
+		speech_base_url VARCHAR DEFAULT '',
+		speech_model VARCHAR DEFAULT '',
+		speech_no_auth BOOLEAN DEFAULT 0,
+		whisper_binary VARCHAR DEFAULT '',
+		whisper_model VARCHAR DEFAULT '',
+		ffmpeg_binary VARCHAR DEFAULT '',
+		piper_binary VARCHAR DEFAULT '',
+		piper_model VARCHAR DEFAULT '',
+		max_retries INTEGER DEFAULT 3,
+		chat_temperature REAL,
+		chat_max_tokens INTEGER,
+		chat_top_p REAL,
+		speech_voice VARCHAR DEFAULT '',
+		elevenlabs_stability REAL DEFAULT 0.5,
+		elevenlabs_similarity REAL DEFAULT 0.75,
+		budget_session REAL DEFAULT 0,
+		budget_monthly REAL DEFAULT 0,
+		budget_soft_at REAL DEFAULT 0.8,
+		budget_action VARCHAR DEFAULT 'stop',
+		budget_chat_model VARCHAR DEFAULT '',
+		azure_key VARCHAR DEFAULT '',
+		azure_api_version VARCHAR DEFAULT '2024-10-21',
+		anthropic_key VARCHAR DEFAULT '',
+		speech_cache BOOLEAN DEFAULT 1,
+		speech_cache_size INTEGER DEFAULT 200,
+		chat_fallbacks VARCHAR DEFAULT '',
+		transcript_fallbacks VARCHAR DEFAULT '',
+		speech_fallbacks VARCHAR DEFAULT ''
 	);`
 
-	settingsData = "SELECT id, openai_key, elevenlabs_key FROM settings LIMIT 1;"
+	// the settings are a single row
+	settingInsert = "INSERT OR IGNORE INTO settings (id) VALUES (1);"
 
-	settingInsert = "INSERT INTO settings (id) VALUES (1);"
+	columnExists = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?;"
 
 	chatUsersSchema = `CREATE TABLE IF NOT EXISTS chat_users (
 		id VARCHAR PRIMARY KEY,
 		secret VARCHAR NOT NULL,
-		language VARCHAR DEFAULT 'en'
+		language VARCHAR DEFAULT 'en',
+		voice VARCHAR DEFAULT '',
+		voice_stability REAL,
+		voice_similarity REAL,
+		voice_style REAL
 	);`
 
 	chatsSchema = `CREATE TABLE IF NOT EXISTS chats (
@@ -33,56 +81,278 @@ const (
 		audio VARCHAR,
 		FOREIGN KEY(chat_user_id) REFERENCES chat_users(id)
 	);`
+
+	usagesSchema = `CREATE TABLE IF NOT EXISTS usages (
+		id VARCHAR PRIMARY KEY,
+		chat_user_id VARCHAR,
+		capability VARCHAR,
+		model VARCHAR,
+		input_tokens INTEGER DEFAULT 0,
+		output_tokens INTEGER DEFAULT 0,
+		audio_seconds REAL DEFAULT 0,
+		characters INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(chat_user_id) REFERENCES chat_users(id)
+	);`
+
+	// prices are in USD, per million tokens, per minute of audio and per million characters
+	pricesSchema = `CREATE TABLE IF NOT EXISTS prices (
+		model VARCHAR PRIMARY KEY,
+		input_price REAL DEFAULT 0,
+		output_price REAL DEFAULT 0,
+		audio_price REAL DEFAULT 0,
+		character_price REAL DEFAULT 0
+	);`
+
+	// priceInsert seeds the default models without overwriting prices edited by the user
+	priceInsert = `INSERT OR IGNORE INTO prices (model, input_price, output_price, audio_price, character_price) VALUES
+		('gpt-4o-mini-2024-07-18', 0.15, 0.6, 0, 0),
+		('gpt-4o-mini', 0.15, 0.6, 0, 0),
+		('gpt-4o', 2.5, 10, 0, 0),
+		('whisper-1', 0, 0, 0.006, 0),
+		('tts-1', 0, 0, 0, 15),
+		('tts-1-hd', 0, 0, 0, 30),
+		('claude-3-5-haiku-20241022', 0.8, 4, 0, 0),
+		('claude-sonnet-4-20250514', 3, 15, 0, 0);`
 )
 
-func New() *sql.DB {
-	db, err := sql.Open("sqlite", "file:app.db?cache=shared&mode=rwc")
+// column is added to an existing table when it is missing from a database older than the baseline
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+var chatUsersColumns = []column{
+	{"chat_users", "voice", "VARCHAR DEFAULT ''"},
+	{"chat_users", "voice_stability", "REAL"},
+	{"chat_users", "voice_similarity", "REAL"},
+	{"chat_users", "voice_style", "REAL"},
+}
+
+var settingsColumns = []column{
+	{"settings", "chat_provider", "VARCHAR DEFAULT 'openai'"},
+	{"settings", "transcript_provider", "VARCHAR DEFAULT 'openai'"},
+	{"settings", "speech_provider", "VARCHAR DEFAULT 'openai'"},
+	{"settings", "chat_base_url", "VARCHAR DEFAULT ''"},
+	{"settings", "chat_model", "VARCHAR DEFAULT ''"},
+	{"settings", "chat_no_auth", "BOOLEAN DEFAULT 0"},
+	{"settings", "transcript_base_url", "VARCHAR DEFAULT ''"},
+	{"settings", "transcript_model", "VARCHAR DEFAULT ''"},
+	{"settings", "transcript_no_auth", "BOOLEAN DEFAULT 0"},
+	{"settings", "speech_base_url", "VARCHAR DEFAULT ''"},
+	{"settings", "speech_model", "VARCHAR DEFAULT ''"},
+	{"settings", "speech_no_auth", "BOOLEAN DEFAULT 0"},
+	{"settings", "whisper_binary", "VARCHAR DEFAULT ''"},
+	{"settings", "whisper_model", "VARCHAR DEFAULT ''"},
+	{"settings", "ffmpeg_binary", "VARCHAR DEFAULT ''"},
+	{"settings", "piper_binary", "VARCHAR DEFAULT ''"},
+	{"settings", "piper_model", "VARCHAR DEFAULT ''"},
+	{"settings", "max_retries", "INTEGER DEFAULT 3"},
+	{"settings", "chat_temperature", "REAL"},
+	{"settings", "chat_max_tokens", "INTEGER"},
+	{"settings", "chat_top_p", "REAL"},
+	{"settings", "speech_voice", "VARCHAR DEFAULT ''"},
+	{"settings", "elevenlabs_stability", "REAL DEFAULT 0.5"},
+	{"settings", "elevenlabs_similarity", "REAL DEFAULT 0.75"},
+	{"settings", "budget_session", "REAL DEFAULT 0"},
+	{"settings", "budget_monthly", "REAL DEFAULT 0"},
+	{"settings", "budget_soft_at", "REAL DEFAULT 0.8"},
+	{"settings", "budget_action", "VARCHAR DEFAULT 'stop'"},
+	{"settings", "budget_chat_model", "VARCHAR DEFAULT ''"},
+	{"settings", "azure_key", "VARCHAR DEFAULT ''"},
+	{"settings", "azure_api_version", "VARCHAR DEFAULT '2024-10-21'"},
+	{"settings", "anthropic_key", "VARCHAR DEFAULT ''"},
+	{"settings", "speech_cache", "BOOLEAN DEFAULT 1"},
+	{"settings", "speech_cache_size", "INTEGER DEFAULT 200"},
+	{"settings", "chat_fallbacks", "VARCHAR DEFAULT ''"},
+	{"settings", "transcript_fallbacks", "VARCHAR DEFAULT ''"},
+	{"settings", "speech_fallbacks", "VARCHAR DEFAULT ''"},
+}
+
+// New opens the database at the path and brings its schema up to date, a failed migration is returned
+// with the backup taken before it so the app can report it instead of crashing
+func New(path string, blobs *blob.Store) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the database directory: %v", err)
+	}
+
+	if err := relocateLegacy(path); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?cache=shared&mode=rwc", filepath.ToSlash(path)))
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to open the database: %v", err)
 	}
 
-	migrate(db)
+	if err := migrate(db, path, blobs); err != nil {
+		db.Close()
+
+		return nil, err
+	}
 
-	return db
+	return db, nil
 }
 
-func migrate(db *sql.DB) {
-	tx, err := db.Begin()
-	if err != nil {
-		log.Fatal(err)
+// baseline creates the schema as it was before migrations were versioned,
+// adding the columns an older database is missing, every later change is a migration of its own
+func baseline(tx *sql.Tx, _ *blob.Store) error {
+	for _, schema := range []string{settingsSchema, chatUsersSchema, chatsSchema, usagesSchema, pricesSchema} {
+		if _, err := tx.Exec(schema); err != nil {
+			return err
+		}
 	}
-	defer tx.Rollback()
 
-	_, err = tx.Exec(settingsSchema)
-	if err != nil {
-		log.Fatal(err)
+	for _, col := range append(settingsColumns, chatUsersColumns...) {
+		if err := addColumn(tx, col); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(settingInsert); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(priceInsert)
+	return err
+}
+
+func addColumn(tx *sql.Tx, col column) error {
+	var count int
+	if err := tx.QueryRow(columnExists, col.table, col.name).Scan(&count); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
 	}
 
-	var id int
-	var openaiKey, elevenlabsKey string
-	err = tx.QueryRow(settingsData).Scan(&id, &openaiKey, &elevenlabsKey)
-	if err != nil && err == sql.ErrNoRows {
-		log.Default().Println("No settings found, creating default settings")
+	_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", col.table, col.name, col.definition))
+	return err
+}
 
-		if _, err := tx.Exec(settingInsert); err != nil {
-			log.Fatal(err)
+// audioBlobs moves the base64 audio of the chats to the blob store, keeping the key, format and duration,
+// the greeting that was copied on the system prompt is dropped
+func audioBlobs(tx *sql.Tx, blobs *blob.Store) error {
+	for _, stmt := range []string{
+		"ALTER TABLE chats ADD COLUMN audio_key VARCHAR DEFAULT '';",
+		"ALTER TABLE chats ADD COLUMN audio_format VARCHAR DEFAULT '';",
+		"ALTER TABLE chats ADD COLUMN audio_duration REAL DEFAULT 0;",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
 		}
-	} else if err != nil {
-		log.Fatal(err)
 	}
 
-	_, err = tx.Exec(chatUsersSchema)
+	// the ids are read first so only one blob is in memory at a time
+	rows, err := tx.Query("SELECT id FROM chats WHERE role != 'system' AND audio IS NOT NULL AND audio != '';")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	_, err = tx.Exec(chatsSchema)
-	if err != nil {
-		log.Fatal(err)
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+
+		ids = append(ids, id)
 	}
+	rows.Close()
 
-	err = tx.Commit()
-	if err != nil {
-		log.Fatal(err)
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		var encoded string
+		if err := tx.QueryRow("SELECT audio FROM chats WHERE id = ?;", id).Scan(&encoded); err != nil {
+			return err
+		}
+
+		audio, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			log.Default().Printf("failed to decode the audio of chat %s: %v", id, err)
+			continue
+		}
+
+		key, err := blobs.Put(audio)
+		if err != nil {
+			return fmt.Errorf("failed to store the audio of chat %s: %v", id, err)
+		}
+
+		if _, err := tx.Exec("UPDATE chats SET audio_key = ?, audio_format = ?, audio_duration = ? WHERE id = ?;",
+			key, speech.Format(audio), speech.Duration(audio), id); err != nil {
+			return err
+		}
 	}
+
+	_, err = tx.Exec("ALTER TABLE chats DROP COLUMN audio;")
+	return err
+}
+
+// chatSequence numbers the chats of every session in the order they were inserted,
+// so the history no longer depends on the order sqlite happens to return the rows in
+func chatSequence(tx *sql.Tx, _ *blob.Store) error {
+	for _, stmt := range []string{
+		"ALTER TABLE chats ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;",
+		`UPDATE chats SET sequence = (
+			SELECT numbered.sequence FROM (
+				SELECT rowid, ROW_NUMBER() OVER (PARTITION BY chat_user_id ORDER BY rowid) AS sequence FROM chats
+			) AS numbered WHERE numbered.rowid = chats.rowid
+		);`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS chats_chat_user_id_sequence ON chats (chat_user_id, sequence);",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sessionLifecycle keeps the configuration of every interview and when it started and ended,
+// older sessions are dated by their first recorded usage as nothing else tells when they started
+func sessionLifecycle(tx *sql.Tx, _ *blob.Store) error {
+	for _, stmt := range []string{
+		"ALTER TABLE chat_users ADD COLUMN role VARCHAR DEFAULT '';",
+		"ALTER TABLE chat_users ADD COLUMN skills VARCHAR DEFAULT '[]';",
+		"ALTER TABLE chat_users ADD COLUMN chat_provider VARCHAR DEFAULT '';",
+		"ALTER TABLE chat_users ADD COLUMN chat_model VARCHAR DEFAULT '';",
+		"ALTER TABLE chat_users ADD COLUMN transcript_provider VARCHAR DEFAULT '';",
+		"ALTER TABLE chat_users ADD COLUMN transcript_model VARCHAR DEFAULT '';",
+		"ALTER TABLE chat_users ADD COLUMN speech_provider VARCHAR DEFAULT '';",
+		"ALTER TABLE chat_users ADD COLUMN speech_model VARCHAR DEFAULT '';",
+		// sqlite can't add a column defaulting to the current time, the insert sets it
+		"ALTER TABLE chat_users ADD COLUMN created_at DATETIME;",
+		"ALTER TABLE chat_users ADD COLUMN ended_at DATETIME;",
+		"ALTER TABLE chat_users ADD COLUMN status VARCHAR DEFAULT 'active';",
+		"UPDATE chat_users SET created_at = (SELECT MIN(usages.created_at) FROM usages WHERE usages.chat_user_id = chat_users.id);",
+		"CREATE INDEX IF NOT EXISTS chat_users_status_created_at ON chat_users (status, created_at);",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sessionActivity stamps the last activity of a session, which is when it is considered abandoned from,
+// existing sessions were last active at their latest usage, or at their start
+func sessionActivity(tx *sql.Tx, _ *blob.Store) error {
+	for _, stmt := range []string{
+		"ALTER TABLE chat_users ADD COLUMN updated_at DATETIME;",
+		"UPDATE chat_users SET updated_at = COALESCE((SELECT MAX(usages.created_at) FROM usages WHERE usages.chat_user_id = chat_users.id), created_at);",
+		"CREATE INDEX IF NOT EXISTS chat_users_status_updated_at ON chat_users (status, updated_at);",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/blob"
+)
+
+const (
+	migrationsSchema = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	migrationVersion = "SELECT COALESCE(MAX(version), 0) FROM schema_migrations;"
+
+	migrationInsert = "INSERT INTO schema_migrations (version, name) VALUES (?, ?);"
+
+	// tableCount tells a new database, which has nothing to back up, from an existing one
+	tableCount = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence');"
+)
+
+// migration moves the schema forward by one version, it runs in a transaction with the record of its version,
+// the blob store is where data moved out of the database goes
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx, blobs *blob.Store) error
+}
+
+// migrations are applied in order and never edited once released, a change to the schema is a new migration
+var migrations = []migration{
+	{1, "baseline", baseline},
+	{2, "audio blobs", audioBlobs},
+	{3, "chat sequence", chatSequence},
+	{4, "session lifecycle", sessionLifecycle},
+	{5, "session activity", sessionActivity},
+}
+
+// migrate applies the migrations the database hasn't run yet,
+// an existing database is backed up next to it first and compacted after
+func migrate(db *sql.DB, path string, blobs *blob.Store) error {
+	if _, err := db.Exec(migrationsSchema); err != nil {
+		return fmt.Errorf("failed to create the migrations table: %v", err)
+	}
+
+	var version int
+	if err := db.QueryRow(migrationVersion).Scan(&version); err != nil {
+		return fmt.Errorf("failed to get the schema version: %v", err)
+	}
+
+	pending := []migration{}
+	for _, m := range migrations {
+		if m.version > version {
+			pending = append(pending, m)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	backup, err := backupDatabase(db, path, version)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if err := apply(db, m, blobs); err != nil {
+			if backup == "" {
+				return fmt.Errorf("failed to apply migration %d %s: %v", m.version, m.name, err)
+			}
+
+			return fmt.Errorf("failed to apply migration %d %s: %v, the database before the upgrade is saved at %s", m.version, m.name, err, backup)
+		}
+	}
+
+	// migrations moving data out leave free pages behind
+	if backup != "" {
+		if _, err := db.Exec("VACUUM;"); err != nil {
+			log.Default().Println("failed to compact the database:", err)
+		}
+	}
+
+	return nil
+}
+
+func apply(db *sql.DB, m migration, blobs *blob.Store) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx, blobs); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(migrationInsert, m.version, m.name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// backupDatabase copies an existing database before it is migrated from the version,
+// it returns an empty path when the database is new
+func backupDatabase(db *sql.DB, path string, version int) (string, error) {
+	var tables int
+	if err := db.QueryRow(tableCount).Scan(&tables); err != nil {
+		return "", fmt.Errorf("failed to inspect the database: %v", err)
+	}
+
+	if tables == 0 {
+		return "", nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, version, time.Now().Format("20060102150405"))
+	if _, err := os.Stat(backup); err == nil {
+		return "", fmt.Errorf("failed to back up the database: %s already exists", backup)
+	}
+
+	// vacuum into writes a consistent copy even while the database is open
+	if _, err := db.Exec("VACUUM INTO ?;", backup); err != nil {
+		return "", fmt.Errorf("failed to back up the database: %v", err)
+	}
+
+	return backup, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ENV_PATH overrides the location of the database, the -db flag takes precedence over it
+	ENV_PATH = "INTERVIEW_APP_DB"
+
+	appDir   = "interview-app"
+	fileName = "app.db"
+)
+
+// legacyFiles are the database and the journals sqlite may leave next to it
+var legacyFiles = []string{"", "-journal", "-wal", "-shm"}
+
+// Resolve picks the database path from the flag, then the environment, then the user's config directory
+func Resolve(flagPath string) (string, error) {
+	if flagPath != "" {
+		return filepath.Abs(flagPath)
+	}
+
+	if envPath := os.Getenv(ENV_PATH); envPath != "" {
+		return filepath.Abs(envPath)
+	}
+
+	return DefaultPath()
+}
+
+// DefaultPath is the database under the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory: %v", err)
+	}
+
+	return filepath.Join(dir, appDir, fileName), nil
+}
+
+// legacyPaths are where older versions created the database, relative to the working directory
+// and next to the executable
+func legacyPaths() []string {
+	paths := []string{}
+	if wd, err := os.Getwd(); err == nil {
+		paths = append(paths, filepath.Join(wd, fileName))
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(exe), fileName))
+	}
+
+	return paths
+}
+
+// relocateLegacy moves the database of an older version to the path, once, when nothing is there yet
+func relocateLegacy(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	for _, legacy := range legacyPaths() {
+		if legacy == path {
+			return nil
+		}
+
+		if _, err := os.Stat(legacy); err != nil {
+			continue
+		}
+
+		for _, suffix := range legacyFiles {
+			if _, err := os.Stat(legacy + suffix); err != nil {
+				continue
+			}
+
+			if err := moveFile(legacy+suffix, path+suffix); err != nil {
+				return fmt.Errorf("failed to move the database from %s: %v", legacy, err)
+			}
+		}
+
+		log.Default().Printf("moved the database from %s to %s", legacy, path)
+
+		return nil
+	}
+
+	return nil
+}
+
+// moveFile renames the file, copying it when the destination is on another volume
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+
+		return err
+	}
+
+	in.Close()
+
+	return os.Remove(src)
+}
@@ -7,11 +7,13 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 
 	"github.com/madeindra/interview-app/internal/elevenlabs/model"
+	"github.com/madeindra/interview-app/internal/provider"
 )
 
-func (c *ElevenLab) Speechify(apiKey, input string) (io.ReadCloser, error) {
+func (c *ElevenLab) Speechify(ctx context.Context, input string) (io.ReadCloser, error) {
 	url, err := url.JoinPath(c.baseURL, "text-to-speech", c.ttsVoice)
 	if err != nil {
 		return nil, err
@@ -20,7 +22,7 @@ func (c *ElevenLab) Speechify(apiKey, input string) (io.ReadCloser, error) {
 	ttsReq := model.TTSRequest{
 		Text:         input,
 		ModelID:      c.ttsModel,
-		VoiceSetting: defaultVoiceSetting,
+		VoiceSetting: c.voice,
 	}
 
 	body, err := json.Marshal(ttsReq)
@@ -28,15 +30,15 @@ func (c *ElevenLab) Speechify(apiKey, input string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("xi-api-key", apiKey)
+	c.setAuthorization(req)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -47,3 +49,86 @@ func (c *ElevenLab) Speechify(apiKey, input string) (io.ReadCloser, error) {
 
 	return respBody, nil
 }
+
+// Models lists the ids of the models that can serve the capability, which is only ever speech
+func (c *ElevenLab) Models(ctx context.Context, capability provider.Capability) ([]string, error) {
+	if capability != provider.CAPABILITY_SPEECH {
+		return []string{}, nil
+	}
+
+	url, err := url.JoinPath(c.baseURL, "models")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setAuthorization(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := getResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var modelsResp []model.Model
+	if err := json.NewDecoder(respBody).Decode(&modelsResp); err != nil {
+		return nil, err
+	}
+
+	models := []string{}
+	for _, m := range modelsResp {
+		if m.CanDoTextToSpeech {
+			models = append(models, m.ModelID)
+		}
+	}
+
+	sort.Strings(models)
+
+	return models, nil
+}
+
+// Voices lists the premade voices and the ones added to the account
+func (c *ElevenLab) Voices(ctx context.Context) ([]model.Voice, error) {
+	url, err := url.JoinPath(c.baseURL, "voices")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setAuthorization(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := getResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var voicesResp model.VoiceListResponse
+	if err := json.NewDecoder(respBody).Decode(&voicesResp); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(voicesResp.Voices, func(i, j int) bool {
+		return voicesResp.Voices[i].Name < voicesResp.Voices[j].Name
+	})
+
+	return voicesResp.Voices, nil
+}
@@ -1,25 +1,31 @@
 package elevenlabs
 
 import (
-	"io"
+	"fmt"
+	"net/http"
 
 	"github.com/madeindra/interview-app/internal/elevenlabs/model"
+	"github.com/madeindra/interview-app/internal/httpclient"
+	"github.com/madeindra/interview-app/internal/provider"
 )
 
-type Client interface {
-	TextToSpeech(string) (io.ReadCloser, error)
-}
-
 type ElevenLab struct {
+	client   *http.Client
+	apiKey   string
 	baseURL  string
 	ttsModel string
 	ttsVoice string
+	voice    model.VoiceSetting
+	noAuth   bool
 }
 
+type Option func(*ElevenLab)
+
 const (
-	baseURL  = "https://api.elevenlabs.io/v1"
-	ttsModel = "eleven_multilingual_v2"
-	ttsVoice = "cgSgspJ2msm6clMCkdW9"
+	baseURL     = "https://api.elevenlabs.io/v1"
+	ttsModel    = "eleven_multilingual_v2"
+	ttsVoice    = "cgSgspJ2msm6clMCkdW9"
+	ttsMaxInput = 5000
 )
 
 var defaultVoiceSetting = model.VoiceSetting{
@@ -27,10 +33,111 @@ var defaultVoiceSetting = model.VoiceSetting{
 	SimilarityBoost: 0.75,
 }
 
-func New() *ElevenLab {
-	return &ElevenLab{
+func New(apiKey string, opts ...Option) *ElevenLab {
+	c := &ElevenLab{
+		client:   httpclient.Default,
+		apiKey:   apiKey,
 		baseURL:  baseURL,
 		ttsModel: ttsModel,
 		ttsVoice: ttsVoice,
+		voice:    defaultVoiceSetting,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithHTTPClient replaces the shared retrying client
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *ElevenLab) {
+		if client != nil {
+			c.client = client
+		}
+	}
+}
+
+// WithBaseURL overrides the api url, an empty url keeps the default
+func WithBaseURL(url string) Option {
+	return func(c *ElevenLab) {
+		if url != "" {
+			c.baseURL = url
+		}
+	}
+}
+
+// WithTTSModel overrides the text-to-speech model, an empty name keeps the default
+func WithTTSModel(name string) Option {
+	return func(c *ElevenLab) {
+		if name != "" {
+			c.ttsModel = name
+		}
+	}
+}
+
+// WithVoice overrides the voice id, an empty id keeps the default
+func WithVoice(id string) Option {
+	return func(c *ElevenLab) {
+		if id != "" {
+			c.ttsVoice = id
+		}
+	}
+}
+
+// WithVoiceSetting overrides the stability, similarity and style of the voice
+func WithVoiceSetting(setting model.VoiceSetting) Option {
+	return func(c *ElevenLab) {
+		c.voice = setting
 	}
 }
+
+// WithNoAuth stops sending the xi-api-key header
+func WithNoAuth(noAuth bool) Option {
+	return func(c *ElevenLab) {
+		c.noAuth = noAuth
+	}
+}
+
+// Clone copies the client with the options applied, such as the voice chosen for a single interview
+func (c *ElevenLab) Clone(opts ...Option) *ElevenLab {
+	clone := *c
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+
+	return &clone
+}
+
+// Voice is the voice id and setting the speech is synthesized with
+func (c *ElevenLab) Voice() (string, model.VoiceSetting) {
+	return c.ttsVoice, c.voice
+}
+
+// IsSpeechAvailable returns true once a key is set as the multilingual model covers every supported language,
+// without a key the frontend falls back to the WebSpeech API
+func (c *ElevenLab) IsSpeechAvailable(lang string) bool {
+	return c.apiKey != "" || c.noAuth
+}
+
+// MaxInputLength is the character limit of a single text-to-speech request
+func (c *ElevenLab) MaxInputLength() int {
+	return ttsMaxInput
+}
+
+// ModelName is the model serving the capability, which is only ever speech
+func (c *ElevenLab) ModelName(capability provider.Capability) string {
+	if capability != provider.CAPABILITY_SPEECH {
+		return ""
+	}
+
+	return c.ttsModel
+}
+
+// DescribeSpeech names the server, model, voice and voice setting of the text-to-speech
+func (c *ElevenLab) DescribeSpeech() string {
+	return fmt.Sprintf("%s|%s|%s|%s|%g|%g|%g", provider.PROVIDER_ELEVENLABS, c.baseURL, c.ttsModel, c.ttsVoice,
+		c.voice.Stability, c.voice.SimilarityBoost, c.voice.Style)
+}
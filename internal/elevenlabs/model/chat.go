@@ -9,4 +9,5 @@ type TTSRequest struct {
 type VoiceSetting struct {
 	Stability       float32 `json:"stability"`
 	SimilarityBoost float32 `json:"similarity_boost"`
+	Style           float32 `json:"style,omitempty"`
 }
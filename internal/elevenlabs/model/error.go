@@ -0,0 +1,17 @@
+package model
+
+import "encoding/json"
+
+// ErrorResponse carries detail either as an object, a plain message or a list of validation errors
+type ErrorResponse struct {
+	Detail json.RawMessage `json:"detail"`
+}
+
+type ErrorDetail struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Msg string `json:"msg"`
+}
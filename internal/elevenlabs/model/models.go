@@ -0,0 +1,7 @@
+package model
+
+type Model struct {
+	ModelID           string `json:"model_id"`
+	Name              string `json:"name"`
+	CanDoTextToSpeech bool   `json:"can_do_text_to_speech"`
+}
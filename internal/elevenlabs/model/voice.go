@@ -0,0 +1,13 @@
+package model
+
+type VoiceListResponse struct {
+	Voices []Voice `json:"voices"`
+}
+
+type Voice struct {
+	VoiceID    string            `json:"voice_id"`
+	Name       string            `json:"name"`
+	Category   string            `json:"category"`
+	Labels     map[string]string `json:"labels"`
+	PreviewURL string            `json:"preview_url"`
+}
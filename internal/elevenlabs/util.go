@@ -1,9 +1,14 @@
 package elevenlabs
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+
+	"github.com/madeindra/interview-app/internal/elevenlabs/model"
+	"github.com/madeindra/interview-app/internal/provider"
 )
 
 func getResponseBody(resp *http.Response) (io.ReadCloser, error) {
@@ -12,8 +17,66 @@ func getResponseBody(resp *http.Response) (io.ReadCloser, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		defer resp.Body.Close()
+
+		return nil, decodeError(resp)
 	}
 
 	return resp.Body, nil
 }
+
+func (c *ElevenLab) setAuthorization(req *http.Request) {
+	if c.noAuth {
+		return
+	}
+
+	req.Header.Set("xi-api-key", c.apiKey)
+}
+
+// decodeError turns the {"detail": ...} body into a provider error
+func decodeError(resp *http.Response) error {
+	apiErr := &provider.Error{
+		Provider:   provider.PROVIDER_ELEVENLABS,
+		Kind:       provider.KindFromStatus(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+	}
+
+	var errResp model.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || len(errResp.Detail) == 0 {
+		return apiErr
+	}
+
+	var detail model.ErrorDetail
+	var message string
+	var validations []model.ValidationError
+
+	switch {
+	case json.Unmarshal(errResp.Detail, &detail) == nil:
+		apiErr.Code = detail.Status
+		apiErr.Message = detail.Message
+	case json.Unmarshal(errResp.Detail, &message) == nil:
+		apiErr.Message = message
+	case json.Unmarshal(errResp.Detail, &validations) == nil:
+		msgs := make([]string, 0, len(validations))
+		for _, v := range validations {
+			msgs = append(msgs, v.Msg)
+		}
+
+		apiErr.Message = strings.Join(msgs, "; ")
+	}
+
+	switch apiErr.Code {
+	case "invalid_api_key", "needs_authorization":
+		apiErr.Kind = provider.ERROR_INVALID_KEY
+	case "quota_exceeded", "payment_required":
+		apiErr.Kind = provider.ERROR_QUOTA_EXCEEDED
+	case "voice_not_found", "model_not_found":
+		apiErr.Kind = provider.ERROR_MODEL_NOT_FOUND
+	case "max_character_limit_exceeded", "text_too_long":
+		apiErr.Kind = provider.ERROR_CONTENT_TOO_LONG
+	case "too_many_concurrent_requests", "system_busy", "rate_limit_exceeded":
+		apiErr.Kind = provider.ERROR_RATE_LIMITED
+	}
+
+	return apiErr
+}
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	// MaxRetries is the number of attempts after the first one, 0 disables retries
+	MaxRetries int
+
+	// BaseDelay is the backoff before the first retry, doubled on every attempt
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff, a Retry-After longer than this is not waited for
+	MaxDelay time.Duration
+}
+
+const (
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+)
+
+var DefaultConfig = Config{
+	MaxRetries: defaultMaxRetries,
+	BaseDelay:  defaultBaseDelay,
+	MaxDelay:   defaultMaxDelay,
+}
+
+// Default is shared by the providers that aren't given their own client
+var Default = New(DefaultConfig)
+
+// New creates a client that retries failed requests with exponential backoff
+func New(config Config) *http.Client {
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaultBaseDelay
+	}
+
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultMaxDelay
+	}
+
+	return &http.Client{
+		Transport: &Transport{
+			Base:   http.DefaultTransport,
+			Config: config,
+		},
+	}
+}
+
+var errNotRewindable = errors.New("request body cannot be replayed")
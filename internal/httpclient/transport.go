@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Transport retries requests that failed because of rate limits, outages or network errors.
+// Requests that are not idempotent are only retried when the server didn't process them,
+// that is on 429 and 503, unless they carry an Idempotency-Key header.
+type Transport struct {
+	Base   http.RoundTripper
+	Config Config
+}
+
+var idempotentMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodPut:     {},
+	http.MethodDelete:  {},
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+
+		if attempt >= t.Config.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay, ok := t.delay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+
+		// the request body was consumed by the previous attempt
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			log.Default().Printf("retrying %s %s after status %d in %s", req.Method, req.URL.Path, resp.StatusCode, delay)
+		} else {
+			log.Default().Printf("retrying %s %s after error %v in %s", req.Method, req.URL.Path, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		req = next
+	}
+}
+
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(req)
+	}
+
+	return false
+}
+
+// delay honours Retry-After when the server sends it, otherwise it backs off exponentially with full jitter
+func (t *Transport) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return after, after <= t.Config.MaxDelay
+		}
+	}
+
+	backoff := t.Config.BaseDelay << attempt
+	if backoff <= 0 || backoff > t.Config.MaxDelay {
+		backoff = t.Config.MaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)) + 1), true
+}
+
+// retryAfter parses the header as either seconds or an http date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		after := time.Until(date)
+		if after < 0 {
+			after = 0
+		}
+
+		return after, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(req *http.Request) bool {
+	if req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+
+	_, ok := idempotentMethods[req.Method]
+	return ok
+}
+
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errNotRewindable
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	next.Body = body
+	return next, nil
+}
@@ -5,6 +5,7 @@ type Language string
 const (
 	LANGUAGE_ENGLISH    Language = "en"
 	LANGUAGE_INDONESIAN Language = "id"
+	LANGUAGE_DEFAULT    Language = LANGUAGE_ENGLISH
 
 	CODE_ENGLISH    = "en-US"
 	CODE_INDONESIAN = "id-ID"
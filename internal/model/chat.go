@@ -1,35 +1,90 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Chat struct {
-	Text  string `json:"text"`
-	Audio string `json:"audio"`
+	Text        string `json:"text"`
+	Audio       string `json:"audio"`
+	SpeechError string `json:"speechError,omitempty"`
 }
 
 type Entry struct {
-	ID         string `json:"id"`
-	ChatUserID string `json:"chat_user_id"`
-	Role       string `json:"role"`
-	Text       string `json:"text"`
-	Audio      string `json:"audio"`
+	ID         string   `json:"id"`
+	ChatUserID string   `json:"chat_user_id"`
+	Sequence   int      `json:"sequence"`
+	Role       string   `json:"role"`
+	Text       string   `json:"text"`
+	Audio      AudioRef `json:"audio"`
+}
+
+// AudioRef points to the audio of a chat in the blob store, an empty key is a chat without audio.
+// The duration in seconds is the one reported by the transcription or read from wav, ogg or webm audio, zero when unknown
+type AudioRef struct {
+	Key      string  `json:"key"`
+	Format   string  `json:"format"`
+	Duration float64 `json:"duration"`
+}
+
+// NewChat is a chat to be appended to the session
+type NewChat struct {
+	Role  string
+	Text  string
+	Audio AudioRef
 }
 
-func (m *Model) CreateChat(chatUserID, role, text, audio string) (*Entry, error) {
-	id := uuid.New().String()
-	_, err := m.conn.Exec("INSERT INTO chats (id, chat_user_id, role, text, audio) VALUES (?, ?, ?, ?, ?)",
-		id, chatUserID, role, text, audio)
+// CreateChat appends the chat to the session, the sequence is taken in the same statement so it can't be reused
+func (m *Model) CreateChat(chatUserID, role, text string, audio AudioRef) (*Entry, error) {
+	entries, err := m.CreateChats(chatUserID, NewChat{Role: role, Text: text, Audio: audio})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Entry{ID: id, ChatUserID: chatUserID, Role: role, Text: text, Audio: audio}, nil
+	return &entries[0], nil
 }
 
-func (m *Model) GetChatsByChatUserID(chatUserID string) ([]Entry, error) {
-	rows, err := m.conn.Query("SELECT id, chat_user_id, role, text, audio FROM chats WHERE chat_user_id = ?", chatUserID)
+// CreateChats appends the chats to the session in one transaction, so a turn is never stored without its reply
+func (m *Model) CreateChats(chatUserID string, chats ...NewChat) ([]Entry, error) {
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entries := make([]Entry, 0, len(chats))
+	for _, chat := range chats {
+		id := uuid.New().String()
+
+		var sequence int
+		err := tx.QueryRow(`INSERT INTO chats (id, chat_user_id, sequence, role, text, audio_key, audio_format, audio_duration)
+			SELECT ?, ?, COALESCE(MAX(sequence), 0) + 1, ?, ?, ?, ?, ? FROM chats WHERE chat_user_id = ?
+			RETURNING sequence`,
+			id, chatUserID, chat.Role, chat.Text, chat.Audio.Key, chat.Audio.Format, chat.Audio.Duration, chatUserID).Scan(&sequence)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{ID: id, ChatUserID: chatUserID, Sequence: sequence, Role: chat.Role, Text: chat.Text, Audio: chat.Audio})
+	}
+
+	// the session is active as long as it is chatted in
+	if _, err := tx.Exec("UPDATE chat_users SET updated_at = ? WHERE id = ?", time.Now().UTC().Format(time.DateTime), chatUserID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetChatTexts returns the role and text of the session in order, which is all the prompt needs
+func (m *Model) GetChatTexts(chatUserID string) ([]Entry, error) {
+	rows, err := m.conn.Query("SELECT id, chat_user_id, sequence, role, text FROM chats WHERE chat_user_id = ? ORDER BY sequence", chatUserID)
 	if err != nil {
 		return nil, err
 	}
@@ -38,12 +93,69 @@ func (m *Model) GetChatsByChatUserID(chatUserID string) ([]Entry, error) {
 	var chats []Entry
 	for rows.Next() {
 		var chat Entry
-		err := rows.Scan(&chat.ID, &chat.ChatUserID, &chat.Role, &chat.Text, &chat.Audio)
+		err := rows.Scan(&chat.ID, &chat.ChatUserID, &chat.Sequence, &chat.Role, &chat.Text)
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, chat)
+	}
+
+	return chats, rows.Err()
+}
+
+// GetChatPage returns up to limit turns of the session after the sequence, with the reference to their audio,
+// the system prompt isn't a turn of the interview so it is left out
+func (m *Model) GetChatPage(chatUserID string, afterSequence, limit int) ([]Entry, error) {
+	rows, err := m.conn.Query(`SELECT id, chat_user_id, sequence, role, text, audio_key, audio_format, audio_duration
+		FROM chats WHERE chat_user_id = ? AND sequence > ? AND role != 'system'
+		ORDER BY sequence LIMIT ?`, chatUserID, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chats := []Entry{}
+	for rows.Next() {
+		var chat Entry
+		err := rows.Scan(&chat.ID, &chat.ChatUserID, &chat.Sequence, &chat.Role, &chat.Text, &chat.Audio.Key, &chat.Audio.Format, &chat.Audio.Duration)
 		if err != nil {
 			return nil, err
 		}
 		chats = append(chats, chat)
 	}
 
-	return chats, nil
+	return chats, rows.Err()
+}
+
+func (m *Model) GetChat(id string) (*Entry, error) {
+	var chat Entry
+	err := m.conn.QueryRow("SELECT id, chat_user_id, sequence, role, text, audio_key, audio_format, audio_duration FROM chats WHERE id = ?", id).Scan(
+		&chat.ID, &chat.ChatUserID, &chat.Sequence, &chat.Role, &chat.Text, &chat.Audio.Key, &chat.Audio.Format, &chat.Audio.Duration,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat, nil
+}
+
+// GetAudioKeys returns every blob key referenced by a chat, the blobs of the other keys are orphans
+func (m *Model) GetAudioKeys() (map[string]struct{}, error) {
+	rows, err := m.conn.Query("SELECT DISTINCT audio_key FROM chats WHERE audio_key != ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := map[string]struct{}{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+
+		keys[key] = struct{}{}
+	}
+
+	return keys, rows.Err()
 }
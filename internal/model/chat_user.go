@@ -1,31 +1,178 @@
 package model
 
 import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+type SessionStatus string
+
+const (
+	SESSION_ACTIVE    SessionStatus = "active"
+	SESSION_ENDED     SessionStatus = "ended"
+	SESSION_ABANDONED SessionStatus = "abandoned"
+)
+
+// ChatUser is an interview session, there is no separate persona setting so the voice options stand in for
+// the persona of the interviewer, and the providers and models are those it started with
 type ChatUser struct {
-	ID       string `json:"id"`
-	Secret   string `json:"secret"`
-	Language string `json:"language"`
+	ID       string       `json:"id"`
+	Secret   string       `json:"-"`
+	Language string       `json:"language"`
+	Voice    VoiceOptions `json:"voice"`
+
+	Role   string   `json:"role"`
+	Skills []string `json:"skills"`
+
+	ChatProvider       string `json:"chatProvider"`
+	ChatModel          string `json:"chatModel"`
+	TranscriptProvider string `json:"transcriptProvider"`
+	TranscriptModel    string `json:"transcriptModel"`
+	SpeechProvider     string `json:"speechProvider"`
+	SpeechModel        string `json:"speechModel"`
+
+	// the start is unknown for sessions older than the lifecycle,
+	// the update is the last chat or change of status
+	CreatedAt *time.Time    `json:"createdAt"`
+	UpdatedAt *time.Time    `json:"updatedAt"`
+	EndedAt   *time.Time    `json:"endedAt"`
+	Status    SessionStatus `json:"status"`
 }
 
-func (m *Model) CreateChatUser(secret, language string) (*ChatUser, error) {
-	id := uuid.New().String()
-	_, err := m.conn.Exec("INSERT INTO chat_users (id, secret, language) VALUES (?, ?, ?)", id, secret, language)
+const chatUserColumns = `id, secret, language, voice, voice_stability, voice_similarity, voice_style,
+	role, skills,
+	chat_provider, chat_model, transcript_provider, transcript_model, speech_provider, speech_model,
+	created_at, updated_at, ended_at, status`
+
+// CreateChatUser starts an active session with the configuration of the user, its id and start are set here
+func (m *Model) CreateChatUser(user ChatUser) (*ChatUser, error) {
+	if user.Skills == nil {
+		user.Skills = []string{}
+	}
+
+	skills, err := json.Marshal(user.Skills)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ChatUser{ID: id, Secret: secret}, nil
+	now := time.Now().UTC().Truncate(time.Second)
+	user.ID = uuid.New().String()
+	user.CreatedAt = &now
+	user.UpdatedAt = &now
+	user.Status = SESSION_ACTIVE
+
+	_, err = m.conn.Exec("INSERT INTO chat_users ("+chatUserColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		user.ID, user.Secret, user.Language, user.Voice.Voice, user.Voice.Stability, user.Voice.Similarity, user.Voice.Style,
+		user.Role, string(skills),
+		user.ChatProvider, user.ChatModel, user.TranscriptProvider, user.TranscriptModel, user.SpeechProvider, user.SpeechModel,
+		now.Format(time.DateTime), now.Format(time.DateTime), nil, user.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
 }
 
 func (m *Model) GetChatUser(id string) (*ChatUser, error) {
+	return scanChatUser(m.conn.QueryRow("SELECT "+chatUserColumns+" FROM chat_users WHERE id = ?", id))
+}
+
+// GetChatUsers lists the sessions with the status, or every session when it is empty, the latest first
+func (m *Model) GetChatUsers(status SessionStatus, offset, limit int) ([]ChatUser, error) {
+	rows, err := m.conn.Query("SELECT "+chatUserColumns+` FROM chat_users
+		WHERE ? = '' OR status = ?
+		ORDER BY created_at IS NULL, created_at DESC, id
+		LIMIT ? OFFSET ?`, status, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []ChatUser{}
+	for rows.Next() {
+		user, err := scanChatUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+// UpdateChatUserStatus moves the session to the status, ending it stamps the time it ended
+func (m *Model) UpdateChatUserStatus(id string, status SessionStatus) error {
+	now := time.Now().UTC().Format(time.DateTime)
+
+	var endedAt any
+	if status == SESSION_ENDED {
+		endedAt = now
+	}
+
+	_, err := m.conn.Exec("UPDATE chat_users SET status = ?, ended_at = ?, updated_at = ? WHERE id = ?", status, endedAt, now, id)
+	return err
+}
+
+// AbandonChatUsers marks the sessions still active that were last active before the time, or whose activity is unknown,
+// as abandoned and returns how many were
+func (m *Model) AbandonChatUsers(before time.Time) (int64, error) {
+	result, err := m.conn.Exec("UPDATE chat_users SET status = ? WHERE status = ? AND (updated_at IS NULL OR updated_at < ?)",
+		SESSION_ABANDONED, SESSION_ACTIVE, before.UTC().Format(time.DateTime))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanner is a row of either QueryRow or Query
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanChatUser(row scanner) (*ChatUser, error) {
 	var user ChatUser
-	err := m.conn.QueryRow("SELECT id, secret, language FROM chat_users WHERE id = ?", id).Scan(&user.ID, &user.Secret, &user.Language)
+	var skills string
+	var createdAt, updatedAt, endedAt sql.NullString
+
+	err := row.Scan(
+		&user.ID, &user.Secret, &user.Language,
+		&user.Voice.Voice, &user.Voice.Stability, &user.Voice.Similarity, &user.Voice.Style,
+		&user.Role, &skills,
+		&user.ChatProvider, &user.ChatModel, &user.TranscriptProvider, &user.TranscriptModel, &user.SpeechProvider, &user.SpeechModel,
+		&createdAt, &updatedAt, &endedAt, &user.Status,
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := json.Unmarshal([]byte(skills), &user.Skills); err != nil || user.Skills == nil {
+		user.Skills = []string{}
+	}
+
+	user.CreatedAt = parseTime(createdAt)
+	user.UpdatedAt = parseTime(updatedAt)
+	user.EndedAt = parseTime(endedAt)
+
 	return &user, nil
 }
+
+// parseTime reads the utc times written by the models and by CURRENT_TIMESTAMP, nil when there is none
+func parseTime(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	for _, layout := range []string{time.DateTime, time.RFC3339Nano} {
+		if t, err := time.ParseInLocation(layout, value.String, time.UTC); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
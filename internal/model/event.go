@@ -0,0 +1,30 @@
+package model
+
+type ChatDeltaEvent struct {
+	ID    string `json:"id"`
+	Delta string `json:"delta"`
+}
+
+type SpeechChunkEvent struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+	Audio string `json:"audio"`
+}
+
+// BudgetEvent warns that a budget is nearly used up, or that it is and the turn was degraded
+type BudgetEvent struct {
+	ID       string  `json:"id"`
+	Scope    string  `json:"scope"`
+	Spent    float64 `json:"spent"`
+	Limit    float64 `json:"limit"`
+	Exceeded bool    `json:"exceeded"`
+	Action   string  `json:"action,omitempty"`
+}
+
+// ProviderEvent names the provider that served the capability, fallback is true when it isn't the configured one
+type ProviderEvent struct {
+	ID         string `json:"id"`
+	Capability string `json:"capability"`
+	Provider   string `json:"provider"`
+	Fallback   bool   `json:"fallback"`
+}
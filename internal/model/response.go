@@ -5,6 +5,9 @@ type StartChatResponse struct {
 	Secret   string `json:"secret"`
 	Language string `json:"language"`
 
+	// Warnings are the budget warnings of the start, sent before the interview could listen to them
+	Warnings []BudgetEvent `json:"warnings,omitempty"`
+
 	Chat
 }
 
@@ -1,30 +1,9 @@
 package model
 
 import (
-	"database/sql"
-	"errors"
+	"strings"
 )
 
-func (m *Model) GetAPIKey() (string, string, error) {
-	var openAIKey, elevenLabsKey string
-	err := m.conn.QueryRow("SELECT openai_key, elevenlabs_key FROM settings LIMIT 1").Scan(&openAIKey, &elevenLabsKey)
-
-	return openAIKey, elevenLabsKey, err
-}
-
-func (m *Model) AreKeyExist() (bool, error) {
-	openAIKey, elevenLabsKey, err := m.GetAPIKey()
-	if err != nil && errors.Is(err, sql.ErrNoRows) {
-		return false, nil
-	}
-
-	if err != nil {
-		return false, err
-	}
-
-	return openAIKey != "" || elevenLabsKey != "", nil
-}
-
 func (m *Model) UpdateAPIKeys(openAIKey, elevenLabsKey string) error {
 	query := "UPDATE settings SET "
 	args := []any{}
@@ -48,3 +27,156 @@ func (m *Model) UpdateAPIKeys(openAIKey, elevenLabsKey string) error {
 	_, err := m.conn.Exec(query, args...)
 	return err
 }
+
+func (m *Model) UpdateAzureKey(azureKey string) error {
+	_, err := m.conn.Exec("UPDATE settings SET azure_key = ? WHERE id = 1", azureKey)
+	return err
+}
+
+func (m *Model) UpdateAnthropicKey(anthropicKey string) error {
+	_, err := m.conn.Exec("UPDATE settings SET anthropic_key = ? WHERE id = 1", anthropicKey)
+	return err
+}
+
+type Setting struct {
+	OpenAIKey     string `json:"-"`
+	ElevenLabsKey string `json:"-"`
+	AzureKey      string `json:"-"`
+	AnthropicKey  string `json:"-"`
+
+	ChatProvider string `json:"chatProvider"`
+	ChatBaseURL  string `json:"chatBaseUrl"`
+	ChatModel    string `json:"chatModel"`
+	ChatNoAuth   bool   `json:"chatNoAuth"`
+
+	// nil leaves the generation parameter to the provider's default
+	ChatTemperature *float32 `json:"chatTemperature"`
+	ChatMaxTokens   *int     `json:"chatMaxTokens"`
+	ChatTopP        *float32 `json:"chatTopP"`
+
+	TranscriptProvider string `json:"transcriptProvider"`
+	TranscriptBaseURL  string `json:"transcriptBaseUrl"`
+	TranscriptModel    string `json:"transcriptModel"`
+	TranscriptNoAuth   bool   `json:"transcriptNoAuth"`
+
+	SpeechProvider string `json:"speechProvider"`
+	SpeechBaseURL  string `json:"speechBaseUrl"`
+	SpeechModel    string `json:"speechModel"`
+	SpeechNoAuth   bool   `json:"speechNoAuth"`
+	SpeechVoice    string `json:"speechVoice"`
+
+	ElevenLabsStability  float32 `json:"elevenlabsStability"`
+	ElevenLabsSimilarity float32 `json:"elevenlabsSimilarity"`
+
+	// budgets are in USD and zero means unlimited, the soft limit is a fraction of the hard one
+	BudgetSession   float64 `json:"budgetSession"`
+	BudgetMonthly   float64 `json:"budgetMonthly"`
+	BudgetSoftAt    float64 `json:"budgetSoftAt"`
+	BudgetAction    string  `json:"budgetAction"`
+	BudgetChatModel string  `json:"budgetChatModel"`
+
+	// azure deployments are named by the model of each capability
+	AzureAPIVersion string `json:"azureApiVersion"`
+
+	// the size of the speech cache is in megabytes
+	SpeechCache     bool `json:"speechCache"`
+	SpeechCacheSize int  `json:"speechCacheSize"`
+
+	// fallbacks are the providers tried in order when the one of the capability fails
+	ChatFallbacks       []string `json:"chatFallbacks"`
+	TranscriptFallbacks []string `json:"transcriptFallbacks"`
+	SpeechFallbacks     []string `json:"speechFallbacks"`
+
+	WhisperBinary string `json:"whisperBinary"`
+	WhisperModel  string `json:"whisperModel"`
+	FFmpegBinary  string `json:"ffmpegBinary"`
+
+	PiperBinary string `json:"piperBinary"`
+	PiperModel  string `json:"piperModel"`
+
+	MaxRetries int `json:"maxRetries"`
+}
+
+const settingColumns = `chat_provider, chat_base_url, chat_model, chat_no_auth,
+	transcript_provider, transcript_base_url, transcript_model, transcript_no_auth,
+	speech_provider, speech_base_url, speech_model, speech_no_auth,
+	whisper_binary, whisper_model, ffmpeg_binary,
+	piper_binary, piper_model,
+	max_retries,
+	chat_temperature, chat_max_tokens, chat_top_p,
+	speech_voice, elevenlabs_stability, elevenlabs_similarity,
+	budget_session, budget_monthly, budget_soft_at, budget_action, budget_chat_model,
+	azure_api_version,
+	speech_cache, speech_cache_size,
+	chat_fallbacks, transcript_fallbacks, speech_fallbacks`
+
+func (m *Model) GetSetting() (Setting, error) {
+	var s Setting
+	var chatFallbacks, transcriptFallbacks, speechFallbacks string
+	err := m.conn.QueryRow("SELECT openai_key, elevenlabs_key, azure_key, anthropic_key, "+settingColumns+" FROM settings LIMIT 1").Scan(
+		&s.OpenAIKey, &s.ElevenLabsKey, &s.AzureKey, &s.AnthropicKey,
+		&s.ChatProvider, &s.ChatBaseURL, &s.ChatModel, &s.ChatNoAuth,
+		&s.TranscriptProvider, &s.TranscriptBaseURL, &s.TranscriptModel, &s.TranscriptNoAuth,
+		&s.SpeechProvider, &s.SpeechBaseURL, &s.SpeechModel, &s.SpeechNoAuth,
+		&s.WhisperBinary, &s.WhisperModel, &s.FFmpegBinary,
+		&s.PiperBinary, &s.PiperModel,
+		&s.MaxRetries,
+		&s.ChatTemperature, &s.ChatMaxTokens, &s.ChatTopP,
+		&s.SpeechVoice, &s.ElevenLabsStability, &s.ElevenLabsSimilarity,
+		&s.BudgetSession, &s.BudgetMonthly, &s.BudgetSoftAt, &s.BudgetAction, &s.BudgetChatModel,
+		&s.AzureAPIVersion,
+		&s.SpeechCache, &s.SpeechCacheSize,
+		&chatFallbacks, &transcriptFallbacks, &speechFallbacks,
+	)
+
+	s.ChatFallbacks = splitList(chatFallbacks)
+	s.TranscriptFallbacks = splitList(transcriptFallbacks)
+	s.SpeechFallbacks = splitList(speechFallbacks)
+
+	return s, err
+}
+
+// UpdateSetting saves everything except the api keys, which are updated through their own Update*Key
+func (m *Model) UpdateSetting(s Setting) error {
+	_, err := m.conn.Exec(`UPDATE settings SET
+		chat_provider = ?, chat_base_url = ?, chat_model = ?, chat_no_auth = ?,
+		transcript_provider = ?, transcript_base_url = ?, transcript_model = ?, transcript_no_auth = ?,
+		speech_provider = ?, speech_base_url = ?, speech_model = ?, speech_no_auth = ?,
+		whisper_binary = ?, whisper_model = ?, ffmpeg_binary = ?,
+		piper_binary = ?, piper_model = ?,
+		max_retries = ?,
+		chat_temperature = ?, chat_max_tokens = ?, chat_top_p = ?,
+		speech_voice = ?, elevenlabs_stability = ?, elevenlabs_similarity = ?,
+		budget_session = ?, budget_monthly = ?, budget_soft_at = ?, budget_action = ?, budget_chat_model = ?,
+		azure_api_version = ?,
+		speech_cache = ?, speech_cache_size = ?,
+		chat_fallbacks = ?, transcript_fallbacks = ?, speech_fallbacks = ?
+		WHERE id = 1`,
+		s.ChatProvider, s.ChatBaseURL, s.ChatModel, s.ChatNoAuth,
+		s.TranscriptProvider, s.TranscriptBaseURL, s.TranscriptModel, s.TranscriptNoAuth,
+		s.SpeechProvider, s.SpeechBaseURL, s.SpeechModel, s.SpeechNoAuth,
+		s.WhisperBinary, s.WhisperModel, s.FFmpegBinary,
+		s.PiperBinary, s.PiperModel,
+		s.MaxRetries,
+		s.ChatTemperature, s.ChatMaxTokens, s.ChatTopP,
+		s.SpeechVoice, s.ElevenLabsStability, s.ElevenLabsSimilarity,
+		s.BudgetSession, s.BudgetMonthly, s.BudgetSoftAt, s.BudgetAction, s.BudgetChatModel,
+		s.AzureAPIVersion,
+		s.SpeechCache, s.SpeechCacheSize,
+		strings.Join(s.ChatFallbacks, ","), strings.Join(s.TranscriptFallbacks, ","), strings.Join(s.SpeechFallbacks, ","),
+	)
+
+	return err
+}
+
+// splitList reads a comma separated column, an empty column is an empty list
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Usage is what a session or a period consumed and what it cost in USD under the current prices
+type Usage struct {
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	AudioSeconds float64 `json:"audioSeconds"`
+	Characters   int     `json:"characters"`
+	Cost         float64 `json:"cost"`
+}
+
+type UsageSummary struct {
+	Period   string `json:"period"`
+	Sessions int    `json:"sessions"`
+
+	Usage
+}
+
+// UsageRecord is what a single transcription, completion or synthesis consumed,
+// voice previews are recorded without a chat user
+type UsageRecord struct {
+	ChatUserID   string
+	Capability   string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	AudioSeconds float64
+	Characters   int
+}
+
+// Price is in USD, per million tokens, per minute of audio and per million characters
+type Price struct {
+	Model          string  `json:"model"`
+	InputPrice     float64 `json:"inputPrice"`
+	OutputPrice    float64 `json:"outputPrice"`
+	AudioPrice     float64 `json:"audioPrice"`
+	CharacterPrice float64 `json:"characterPrice"`
+}
+
+// usageTotals sums the usage and prices it, models without a price are free
+const usageTotals = `SELECT
+	COALESCE(SUM(u.input_tokens), 0),
+	COALESCE(SUM(u.output_tokens), 0),
+	COALESCE(SUM(u.audio_seconds), 0),
+	COALESCE(SUM(u.characters), 0),
+	COALESCE(SUM(
+		u.input_tokens * COALESCE(p.input_price, 0) / 1000000.0 +
+		u.output_tokens * COALESCE(p.output_price, 0) / 1000000.0 +
+		u.audio_seconds * COALESCE(p.audio_price, 0) / 60.0 +
+		u.characters * COALESCE(p.character_price, 0) / 1000000.0
+	), 0),
+	COUNT(DISTINCT NULLIF(u.chat_user_id, ''))
+	FROM usages u LEFT JOIN prices p ON p.model = u.model`
+
+func (m *Model) CreateUsage(u UsageRecord) error {
+	_, err := m.conn.Exec(`INSERT INTO usages
+		(id, chat_user_id, capability, model, input_tokens, output_tokens, audio_seconds, characters)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), u.ChatUserID, u.Capability, u.Model, u.InputTokens, u.OutputTokens, u.AudioSeconds, u.Characters)
+
+	return err
+}
+
+func (m *Model) GetSessionUsage(chatUserID string) (Usage, error) {
+	var u Usage
+	var sessions int
+	err := m.conn.QueryRow(usageTotals+" WHERE u.chat_user_id = ?", chatUserID).Scan(
+		&u.InputTokens, &u.OutputTokens, &u.AudioSeconds, &u.Characters, &u.Cost, &sessions,
+	)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return u, nil
+}
+
+// GetUsageSince sums the usage recorded from the given time, a zero time covers everything
+func (m *Model) GetUsageSince(since time.Time) (UsageSummary, error) {
+	var s UsageSummary
+	err := m.conn.QueryRow(usageTotals+" WHERE u.created_at >= ?", since.UTC().Format(time.DateTime)).Scan(
+		&s.InputTokens, &s.OutputTokens, &s.AudioSeconds, &s.Characters, &s.Cost, &s.Sessions,
+	)
+	if err != nil {
+		return UsageSummary{}, err
+	}
+
+	return s, nil
+}
+
+func (m *Model) GetPrices() ([]Price, error) {
+	rows, err := m.conn.Query("SELECT model, input_price, output_price, audio_price, character_price FROM prices ORDER BY model")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []Price{}
+	for rows.Next() {
+		var p Price
+		if err := rows.Scan(&p.Model, &p.InputPrice, &p.OutputPrice, &p.AudioPrice, &p.CharacterPrice); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+func (m *Model) UpdatePrice(p Price) error {
+	_, err := m.conn.Exec(`INSERT INTO prices (model, input_price, output_price, audio_price, character_price)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(model) DO UPDATE SET
+		input_price = excluded.input_price, output_price = excluded.output_price,
+		audio_price = excluded.audio_price, character_price = excluded.character_price`,
+		p.Model, p.InputPrice, p.OutputPrice, p.AudioPrice, p.CharacterPrice)
+
+	return err
+}
@@ -0,0 +1,17 @@
+package model
+
+type Voice struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Category   string            `json:"category"`
+	Labels     map[string]string `json:"labels"`
+	PreviewURL string            `json:"previewUrl"`
+}
+
+// VoiceOptions is the elevenlabs voice chosen for an interview, unset fields keep the settings
+type VoiceOptions struct {
+	Voice      string   `json:"voice"`
+	Stability  *float32 `json:"stability"`
+	Similarity *float32 `json:"similarity"`
+	Style      *float32 `json:"style"`
+}
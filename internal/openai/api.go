@@ -10,27 +10,31 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
 )
 
-func (ai *OpenAI) IsKeyValid(apiKey string) (bool, error) {
-	url, err := url.JoinPath(ai.baseURL, "/models")
+func (ai *OpenAI) IsKeyValid(ctx context.Context) (bool, error) {
+	url, err := ai.endpoint("/models", "")
 	if err != nil {
 		return false, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return false, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	ai.setAuthorization(req)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := ai.client.Do(req)
 	if err != nil {
 		return false, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		return false, nil
@@ -39,21 +43,78 @@ func (ai *OpenAI) IsKeyValid(apiKey string) (bool, error) {
 	return true, nil
 }
 
-func (c *OpenAI) Status() (model.Status, error) {
+// Models lists the ids of the models that can serve the capability
+func (ai *OpenAI) Models(ctx context.Context, capability provider.Capability) ([]string, error) {
+	// the data plane of azure lists the models of the resource but not its deployments
+	if ai.azure {
+		return nil, fmt.Errorf("azure deployments can't be listed, please enter the deployment name")
+	}
+
+	url, err := url.JoinPath(ai.baseURL, "/models")
+	if err != nil {
+		log.Default().Println("error joining url path", err)
+
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Default().Println("error creating http request", err)
+
+		return nil, err
+	}
+
+	ai.setAuthorization(req)
+
+	resp, err := ai.client.Do(req)
+	if err != nil {
+		log.Default().Println("error sending http request", err)
+
+		return nil, err
+	}
+
+	var modelsResp model.ModelListResponse
+	err = unmarshalJSONResponse(resp, &modelsResp)
+	if err != nil {
+		log.Default().Println("error unmarshalling models response", err)
+
+		return nil, err
+	}
+
+	models := []string{}
+	for _, m := range modelsResp.Data {
+		// compatible servers name their models freely, so only the official ids can be filtered
+		if ai.baseURL != baseURL || isCapable(m.ID, capability) {
+			models = append(models, m.ID)
+		}
+	}
+
+	sort.Strings(models)
+
+	return models, nil
+}
+
+func (c *OpenAI) Status(ctx context.Context) (model.Status, error) {
+	// the status page only covers the official api
+	if c.baseURL != baseURL {
+		return model.STATUS_UNKNOWN, nil
+	}
+
 	url, err := url.JoinPath(statusURL, "/components.json")
 	if err != nil {
 		return model.STATUS_UNKNOWN, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return model.STATUS_UNKNOWN, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return model.STATUS_UNKNOWN, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		return model.STATUS_UNKNOWN, nil
@@ -83,18 +144,15 @@ func (c *OpenAI) Status() (model.Status, error) {
 	return model.STATUS_UNKNOWN, nil
 }
 
-func (ai *OpenAI) Chat(apiKey string, messages []model.ChatMessage) (model.ChatResponse, error) {
-	url, err := url.JoinPath(ai.baseURL, "/chat/completions")
+func (ai *OpenAI) Chat(ctx context.Context, messages []model.ChatMessage) (model.ChatResponse, error) {
+	url, err := ai.endpoint("/chat/completions", ai.chatModel)
 	if err != nil {
 		log.Default().Println("error joining url path", err)
 
 		return model.ChatResponse{}, err
 	}
 
-	chatReq := model.ChatRequest{
-		Model:    chatModel,
-		Messages: messages,
-	}
+	chatReq := ai.chatRequest(messages)
 
 	body, err := json.Marshal(chatReq)
 	if err != nil {
@@ -103,17 +161,17 @@ func (ai *OpenAI) Chat(apiKey string, messages []model.ChatMessage) (model.ChatR
 		return model.ChatResponse{}, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		log.Default().Println("error creating http request", err)
 
 		return model.ChatResponse{}, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	ai.setAuthorization(req)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := ai.client.Do(req)
 	if err != nil {
 		log.Default().Println("error sending http request", err)
 
@@ -131,14 +189,138 @@ func (ai *OpenAI) Chat(apiKey string, messages []model.ChatMessage) (model.ChatR
 	return chatResp, nil
 }
 
-func (ai *OpenAI) Transcribe(apiKey string, file io.Reader, filename string) (model.TranscriptResponse, error) {
+// chatRequest applies the generation settings, reasoning models take the max tokens as max_completion_tokens
+// and reject any temperature and top p, so those are left to their defaults
+func (ai *OpenAI) chatRequest(messages []model.ChatMessage) model.ChatRequest {
+	chatReq := model.ChatRequest{
+		Model:      ai.chatModel,
+		Messages:   messages,
+		Generation: ai.generation,
+	}
+
+	if isReasoning(ai.chatModel) {
+		chatReq.MaxCompletionTokens = chatReq.MaxTokens
+		chatReq.Generation = model.Generation{}
+	}
+
+	return chatReq
+}
+
+// ChatStream requests the completion as server-sent events, calling onDelta for every content token
+// and returning the assembled completion once the stream is done
+func (ai *OpenAI) ChatStream(ctx context.Context, messages []model.ChatMessage, onDelta func(string)) (model.ChatResponse, error) {
+	url, err := ai.endpoint("/chat/completions", ai.chatModel)
+	if err != nil {
+		log.Default().Println("error joining url path", err)
+
+		return model.ChatResponse{}, err
+	}
+
+	chatReq := ai.chatRequest(messages)
+	chatReq.Stream = true
+	chatReq.StreamOptions = &model.StreamOptions{
+		IncludeUsage: true,
+	}
+
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		log.Default().Println("error marshalling chat request", err)
+
+		return model.ChatResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		log.Default().Println("error creating http request", err)
+
+		return model.ChatResponse{}, err
+	}
+
+	ai.setAuthorization(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := ai.client.Do(req)
+	if err != nil {
+		log.Default().Println("error sending http request", err)
+
+		return model.ChatResponse{}, err
+	}
+
+	respBody, err := getResponseBody(resp)
+	if err != nil {
+		log.Default().Println("error getting response body", err)
+
+		return model.ChatResponse{}, err
+	}
+	defer respBody.Close()
+
+	var content strings.Builder
+	var finishReason, chatModel string
+	var usage model.Usage
+
+	err = readEvents(respBody, func(data []byte) error {
+		var chunk model.ChatStreamResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return err
+		}
+
+		if chunk.Model != "" {
+			chatModel = chunk.Model
+		}
+
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+
+			if choice.Delta.Content == "" {
+				continue
+			}
+
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Default().Println("error reading chat stream", err)
+
+		return model.ChatResponse{}, err
+	}
+
+	chatResp := model.ChatResponse{
+		Model: chatModel,
+		Usage: usage,
+		Choices: []model.Choice{
+			{
+				Message: model.ChatMessage{
+					Role:    model.ROLE_ASSISTANT,
+					Content: content.String(),
+				},
+				FinishReason: finishReason,
+			},
+		},
+	}
+
+	return chatResp, nil
+}
+
+func (ai *OpenAI) Transcribe(ctx context.Context, file io.Reader, filename string) (model.TranscriptResponse, error) {
 	if file == nil {
 		log.Default().Println("audio is nil")
 
 		return model.TranscriptResponse{}, fmt.Errorf("audio is nil")
 	}
 
-	url, err := url.JoinPath(baseURL, "/audio/transcriptions")
+	url, err := ai.endpoint("/audio/transcriptions", ai.transcriptModel)
 	if err != nil {
 		log.Default().Println("error joining url path", err)
 
@@ -162,14 +344,14 @@ func (ai *OpenAI) Transcribe(apiKey string, file io.Reader, filename string) (mo
 		return model.TranscriptResponse{}, err
 	}
 
-	err = writer.WriteField("model", transcriptModel)
+	err = writer.WriteField("model", ai.transcriptModel)
 	if err != nil {
 		log.Default().Println("error writing model field", err)
 
 		return model.TranscriptResponse{}, err
 	}
 
-	err = writer.WriteField("language", transcriptLanguage)
+	err = writer.WriteField("language", ai.transcriptLanguage)
 	if err != nil {
 		log.Default().Println("error writing language field", err)
 
@@ -183,17 +365,17 @@ func (ai *OpenAI) Transcribe(apiKey string, file io.Reader, filename string) (mo
 		return model.TranscriptResponse{}, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		log.Default().Println("error creating http request", err)
 
 		return model.TranscriptResponse{}, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	ai.setAuthorization(req)
 	req.Header.Add("Content-Type", writer.FormDataContentType())
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := ai.client.Do(req)
 	if err != nil {
 		log.Default().Println("error sending http request", err)
 
@@ -217,8 +399,8 @@ func (ai *OpenAI) Transcribe(apiKey string, file io.Reader, filename string) (mo
 	return transcriptResp, nil
 }
 
-func (ai *OpenAI) Speechify(apiKey string, text string) (io.ReadCloser, error) {
-	url, err := url.JoinPath(ai.baseURL, "/audio/speech")
+func (ai *OpenAI) Speechify(ctx context.Context, text string) (io.ReadCloser, error) {
+	url, err := ai.endpoint("/audio/speech", ai.ttsModel)
 	if err != nil {
 		log.Default().Println("error joining url path", err)
 
@@ -226,8 +408,8 @@ func (ai *OpenAI) Speechify(apiKey string, text string) (io.ReadCloser, error) {
 	}
 
 	ttsReq := model.TTSRequest{
-		Model: ttsModel,
-		Voice: ttsVoice,
+		Model: ai.ttsModel,
+		Voice: ai.ttsVoice,
 		Input: text,
 	}
 
@@ -238,17 +420,17 @@ func (ai *OpenAI) Speechify(apiKey string, text string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		log.Default().Println("error creating http request", err)
 
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	ai.setAuthorization(req)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := ai.client.Do(req)
 	if err != nil {
 		log.Default().Println("error sending http request", err)
 
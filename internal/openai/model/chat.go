@@ -8,10 +8,44 @@ type ChatMessage struct {
 type ChatRequest struct {
 	Messages []ChatMessage `json:"messages"`
 	Model    string        `json:"model"`
+	Stream   bool          `json:"stream,omitempty"`
+
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	// MaxCompletionTokens replaces the max tokens of the generation for reasoning models
+	MaxCompletionTokens *int `json:"max_completion_tokens,omitempty"`
+
+	Generation
+}
+
+// Generation is left out of the request when unset so the model's defaults apply
+type Generation struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
 }
 
 type ChatResponse struct {
+	Model   string   `json:"model"`
 	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage"`
+}
+
+type ChatStreamResponse struct {
+	Model   string         `json:"model"`
+	Choices []StreamChoice `json:"choices"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// StreamOptions asks for the usage of a streamed completion, which is sent in a last chunk without choices
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type TTSRequest struct {
@@ -21,5 +55,24 @@ type TTSRequest struct {
 }
 
 type TranscriptResponse struct {
-	Text string `json:"text"`
+	Text     string           `json:"text"`
+	Duration float64          `json:"duration,omitempty"`
+	Usage    *TranscriptUsage `json:"usage,omitempty"`
+}
+
+// TranscriptUsage is billed by the second for whisper and by the token for the newer models
+type TranscriptUsage struct {
+	Type         string  `json:"type"`
+	Seconds      float64 `json:"seconds,omitempty"`
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+}
+
+// Seconds is the duration of the transcribed audio, zero when the provider doesn't report it
+func (t TranscriptResponse) Seconds() float64 {
+	if t.Usage != nil && t.Usage.Seconds > 0 {
+		return t.Usage.Seconds
+	}
+
+	return t.Duration
 }
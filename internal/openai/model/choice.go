@@ -5,3 +5,9 @@ type Choice struct {
 	Message      ChatMessage `json:"message"`
 	FinishReason string      `json:"finish_reason"`
 }
+
+type StreamChoice struct {
+	Index        int         `json:"index"`
+	Delta        ChatMessage `json:"delta"`
+	FinishReason string      `json:"finish_reason"`
+}
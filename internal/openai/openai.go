@@ -1,14 +1,31 @@
 package openai
 
+import (
+	"net/http"
+	"strings"
+
+	"github.com/madeindra/interview-app/internal/httpclient"
+	"github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
 type OpenAI struct {
+	client             *http.Client
+	apiKey             string
 	baseURL            string
 	chatModel          string
 	transcriptModel    string
 	transcriptLanguage string
 	ttsModel           string
 	ttsVoice           string
+	generation         model.Generation
+	noAuth             bool
+	azure              bool
+	apiVersion         string
 }
 
+type Option func(*OpenAI)
+
 const (
 	baseURL            = "https://api.openai.com/v1"
 	statusURL          = "https://status.openai.com/api/v2"
@@ -17,14 +34,30 @@ const (
 	transcriptLanguage = "en"
 	ttsModel           = "tts-1"
 	ttsVoice           = "nova"
+	ttsMaxInput        = 4096
+	azureAPIVersion    = "2024-10-21"
 )
 
 var supportedTranscriptLanguages = map[string]struct{}{
 	"en": {},
 }
 
-func New() *OpenAI {
-	return &OpenAI{
+var supportedVoices = map[string]struct{}{
+	"alloy":   {},
+	"ash":     {},
+	"coral":   {},
+	"echo":    {},
+	"fable":   {},
+	"onyx":    {},
+	"nova":    {},
+	"sage":    {},
+	"shimmer": {},
+}
+
+func New(apiKey string, opts ...Option) *OpenAI {
+	ai := &OpenAI{
+		client:             httpclient.Default,
+		apiKey:             apiKey,
 		baseURL:            baseURL,
 		chatModel:          chatModel,
 		transcriptModel:    transcriptModel,
@@ -32,4 +65,147 @@ func New() *OpenAI {
 		ttsModel:           ttsModel,
 		ttsVoice:           ttsVoice,
 	}
+
+	for _, opt := range opts {
+		opt(ai)
+	}
+
+	return ai
+}
+
+// WithHTTPClient replaces the shared retrying client
+func WithHTTPClient(client *http.Client) Option {
+	return func(ai *OpenAI) {
+		if client != nil {
+			ai.client = client
+		}
+	}
+}
+
+// WithBaseURL points the client to an OpenAI-compatible server, an empty url keeps the default
+func WithBaseURL(url string) Option {
+	return func(ai *OpenAI) {
+		if url != "" {
+			ai.baseURL = url
+		}
+	}
+}
+
+// WithChatModel overrides the chat model, an empty name keeps the default
+func WithChatModel(name string) Option {
+	return func(ai *OpenAI) {
+		if name != "" {
+			ai.chatModel = name
+		}
+	}
+}
+
+// WithTranscriptModel overrides the transcript model, an empty name keeps the default
+func WithTranscriptModel(name string) Option {
+	return func(ai *OpenAI) {
+		if name != "" {
+			ai.transcriptModel = name
+		}
+	}
+}
+
+// WithTranscriptLanguage sets the spoken language of the recordings, an empty language keeps the default
+func WithTranscriptLanguage(lang string) Option {
+	return func(ai *OpenAI) {
+		if lang != "" {
+			ai.transcriptLanguage = lang
+		}
+	}
+}
+
+// Clone copies the client with the options applied, such as the language of a single interview
+func (ai *OpenAI) Clone(opts ...Option) *OpenAI {
+	clone := *ai
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+
+	return &clone
+}
+
+// WithTTSModel overrides the text-to-speech model, an empty name keeps the default
+func WithTTSModel(name string) Option {
+	return func(ai *OpenAI) {
+		if name != "" {
+			ai.ttsModel = name
+		}
+	}
+}
+
+// WithTTSVoice overrides the text-to-speech voice, an empty name keeps the default
+func WithTTSVoice(name string) Option {
+	return func(ai *OpenAI) {
+		if name != "" {
+			ai.ttsVoice = name
+		}
+	}
+}
+
+// WithGeneration sets the temperature, max tokens and top p of every chat request
+func WithGeneration(generation model.Generation) Option {
+	return func(ai *OpenAI) {
+		ai.generation = generation
+	}
+}
+
+// WithNoAuth stops sending the Authorization header, for local servers that don't need a key
+func WithNoAuth(noAuth bool) Option {
+	return func(ai *OpenAI) {
+		ai.noAuth = noAuth
+	}
+}
+
+func (ai *OpenAI) IsSpeechAvailable(lang string) bool {
+	_, ok := supportedTranscriptLanguages[lang]
+	return ok
+}
+
+// WithAzure routes every request to the azure deployment named by the model of the capability,
+// the base url is the endpoint of the azure resource and an empty version keeps the default
+func WithAzure(apiVersion string) Option {
+	return func(ai *OpenAI) {
+		ai.azure = true
+		ai.apiVersion = azureAPIVersion
+
+		if apiVersion != "" {
+			ai.apiVersion = apiVersion
+		}
+	}
+}
+
+// MaxInputLength is the character limit of /audio/speech
+func (ai *OpenAI) MaxInputLength() int {
+	return ttsMaxInput
+}
+
+// ModelName is the model serving the capability
+func (ai *OpenAI) ModelName(capability provider.Capability) string {
+	switch capability {
+	case provider.CAPABILITY_CHAT:
+		return ai.chatModel
+	case provider.CAPABILITY_TRANSCRIPT:
+		return ai.transcriptModel
+	case provider.CAPABILITY_SPEECH:
+		return ai.ttsModel
+	default:
+		return ""
+	}
+}
+
+// DescribeSpeech names the server, model and voice of the text-to-speech
+func (ai *OpenAI) DescribeSpeech() string {
+	return strings.Join([]string{string(provider.PROVIDER_OPENAI), ai.baseURL, ai.ttsModel, ai.ttsVoice}, "|")
+}
+
+// IsVoice reports whether the official api offers the text-to-speech voice
+func IsVoice(name string) bool {
+	_, ok := supportedVoices[name]
+
+	return ok
 }
@@ -1,11 +1,17 @@
 package openai
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
 )
 
 func getResponseBody(resp *http.Response) (io.ReadCloser, error) {
@@ -16,10 +22,12 @@ func getResponseBody(resp *http.Response) (io.ReadCloser, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		log.Default().Printf("unexpected status code: %d", resp.StatusCode)
-		log.Default().Printf("response: %v", resp)
+		defer resp.Body.Close()
+
+		err := decodeError(resp)
+		log.Default().Printf("unexpected status code: %d: %v", resp.StatusCode, err)
 
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, err
 	}
 
 	return resp.Body, nil
@@ -41,3 +49,148 @@ func unmarshalJSONResponse(resp *http.Response, v interface{}) error {
 
 	return json.NewDecoder(respBody).Decode(&v)
 }
+
+func (ai *OpenAI) setAuthorization(req *http.Request) {
+	if ai.noAuth {
+		return
+	}
+
+	if ai.azure {
+		req.Header.Set("api-key", ai.apiKey)
+		return
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ai.apiKey))
+}
+
+// endpoint builds the url of an api path, azure serves it under the deployment with a pinned api version
+// while paths without a deployment, such as /models, sit at the root of the resource
+func (ai *OpenAI) endpoint(path, deployment string) (string, error) {
+	if !ai.azure {
+		return url.JoinPath(ai.baseURL, path)
+	}
+
+	u, err := url.Parse(ai.baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	if deployment == "" {
+		u = u.JoinPath("openai", path)
+	} else {
+		u = u.JoinPath("openai", "deployments", deployment, path)
+	}
+
+	query := u.Query()
+	query.Set("api-version", ai.apiVersion)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// readEvents calls onData with the payload of every server-sent event until the [DONE] marker
+func readEvents(body io.Reader, onData func([]byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return nil
+		}
+
+		if err := onData([]byte(data)); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// decodeError turns the {"error": {...}} body into a provider error
+func decodeError(resp *http.Response) error {
+	apiErr := &provider.Error{
+		Provider:   provider.PROVIDER_OPENAI,
+		Kind:       provider.KindFromStatus(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+	}
+
+	var errResp model.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		return apiErr
+	}
+
+	apiErr.Message = errResp.Error.Message
+
+	// code is a string on most errors but can be null or a number
+	if code, ok := errResp.Error.Code.(string); ok {
+		apiErr.Code = code
+	}
+
+	switch {
+	case apiErr.Code == "invalid_api_key":
+		apiErr.Kind = provider.ERROR_INVALID_KEY
+	case apiErr.Code == "insufficient_quota" || errResp.Error.Type == "insufficient_quota":
+		apiErr.Kind = provider.ERROR_QUOTA_EXCEEDED
+	case apiErr.Code == "model_not_found":
+		apiErr.Kind = provider.ERROR_MODEL_NOT_FOUND
+	case apiErr.Code == "context_length_exceeded" || apiErr.Code == "string_above_max_length":
+		apiErr.Kind = provider.ERROR_CONTENT_TOO_LONG
+	case apiErr.Code == "rate_limit_exceeded":
+		apiErr.Kind = provider.ERROR_RATE_LIMITED
+	}
+
+	return apiErr
+}
+
+var (
+	chatModelPrefixes = []string{"gpt-", "chatgpt-", "o1", "o3", "o4"}
+
+	// reasoning models take max_completion_tokens and no sampling settings
+	reasoningModelPrefixes = []string{"o1", "o3", "o4"}
+
+	// variants of the chat families that don't serve chat completions
+	nonChatModelMarkers = []string{"audio", "realtime", "tts", "transcribe", "search", "image", "instruct", "embedding"}
+)
+
+// isReasoning guesses from the id of an official model whether it is a reasoning model
+func isReasoning(id string) bool {
+	for _, prefix := range reasoningModelPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCapable guesses from the id of an official model whether it can serve the capability
+func isCapable(id string, capability provider.Capability) bool {
+	switch capability {
+	case provider.CAPABILITY_CHAT:
+		for _, marker := range nonChatModelMarkers {
+			if strings.Contains(id, marker) {
+				return false
+			}
+		}
+
+		for _, prefix := range chatModelPrefixes {
+			if strings.HasPrefix(id, prefix) {
+				return true
+			}
+		}
+
+		return false
+	case provider.CAPABILITY_TRANSCRIPT:
+		return strings.HasPrefix(id, "whisper") || strings.Contains(id, "transcribe")
+	case provider.CAPABILITY_SPEECH:
+		return strings.HasPrefix(id, "tts-") || strings.Contains(id, "-tts")
+	default:
+		return false
+	}
+}
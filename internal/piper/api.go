@@ -0,0 +1,49 @@
+package piper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Speechify returns the speech as wav audio
+func (p *Piper) Speechify(ctx context.Context, text string) (io.ReadCloser, error) {
+	dir, err := os.MkdirTemp("", "piper-*")
+	if err != nil {
+		log.Default().Println("error creating temp dir", err)
+
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "speech.wav")
+	cmd := exec.CommandContext(ctx, p.binary,
+		"--model", p.model,
+		"--output_file", output,
+	)
+	cmd.Stdin = strings.NewReader(text)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Default().Println("error running piper", err)
+
+		return nil, fmt.Errorf("piper: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	audio, err := os.ReadFile(output)
+	if err != nil {
+		log.Default().Println("error reading speech", err)
+
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(audio)), nil
+}
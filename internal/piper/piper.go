@@ -0,0 +1,81 @@
+package piper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+type Piper struct {
+	binary string
+	model  string
+
+	once     sync.Once
+	language string
+}
+
+// voiceConfig is the part of the <model>.onnx.json file shipped with every piper voice that we need
+type voiceConfig struct {
+	Language struct {
+		Code string `json:"code"`
+	} `json:"language"`
+}
+
+// New creates a synthesizer that runs the piper executable with a local onnx voice model
+func New(binary, model string) *Piper {
+	return &Piper{
+		binary: binary,
+		model:  model,
+	}
+}
+
+// IsSpeechAvailable compares the language with the one declared in the voice config,
+// the voice is assumed to match when the config can't be read
+func (p *Piper) IsSpeechAvailable(lang string) bool {
+	p.once.Do(p.loadLanguage)
+
+	if p.language == "" {
+		return true
+	}
+
+	return strings.EqualFold(p.language, lang)
+}
+
+// MaxInputLength returns 0 as piper has no input limit
+func (p *Piper) MaxInputLength() int {
+	return 0
+}
+
+func (p *Piper) loadLanguage() {
+	body, err := os.ReadFile(p.model + ".json")
+	if err != nil {
+		return
+	}
+
+	var config voiceConfig
+	if err := json.Unmarshal(body, &config); err != nil {
+		return
+	}
+
+	// voice languages look like en_US while the app uses en
+	code, _, _ := strings.Cut(config.Language.Code, "_")
+	p.language = code
+}
+
+// ModelName is the file name of the onnx voice, only speech is served
+func (p *Piper) ModelName(capability provider.Capability) string {
+	if capability != provider.CAPABILITY_SPEECH {
+		return ""
+	}
+
+	return filepath.Base(p.model)
+}
+
+// DescribeSpeech names the voice model, which decides the audio on its own
+func (p *Piper) DescribeSpeech() string {
+	return string(provider.PROVIDER_PIPER) + "|" + p.model
+}
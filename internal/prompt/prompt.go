@@ -1,4 +1,4 @@
-package openai
+package prompt
 
 import (
 	"bytes"
@@ -7,12 +7,6 @@ import (
 	"text/template"
 )
 
-type ChatAsset struct {
-	SystemPrompt string
-	ChatText     string
-	ChatAudio    string
-}
-
 var (
 	//go:embed templates/chat.en.txt
 	initalChatEN string
@@ -27,7 +21,7 @@ var (
 	systemPromptID string
 )
 
-func (ai *OpenAI) GetSystemPrompt(roleName string, skills []string, language string) (string, error) {
+func GetSystemPrompt(roleName string, skills []string, language string) (string, error) {
 	systemPrompt := systemPromptEN
 	if language == "id" {
 		systemPrompt = systemPromptID
@@ -54,7 +48,7 @@ func (ai *OpenAI) GetSystemPrompt(roleName string, skills []string, language str
 	return buf.String(), nil
 }
 
-func (ai *OpenAI) GetInitialChat(roleName string, language string) (string, error) {
+func GetInitialChat(roleName string, language string) (string, error) {
 	initalChat := initalChatEN
 	if language == "id" {
 		initalChat = initalChatID
@@ -78,12 +72,3 @@ func (ai *OpenAI) GetInitialChat(roleName string, language string) (string, erro
 
 	return buf.String(), nil
 }
-
-func (ai *OpenAI) GetDefaultTranscriptLanguage() string {
-	return ai.transcriptLanguage
-}
-
-func (ai *OpenAI) IsSpeechAvailable(lang string) bool {
-	_, ok := supportedTranscriptLanguages[lang]
-	return ok
-}
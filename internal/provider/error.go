@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type ErrorKind string
+
+const (
+	ERROR_INVALID_KEY      ErrorKind = "invalid_key"
+	ERROR_QUOTA_EXCEEDED   ErrorKind = "quota_exceeded"
+	ERROR_CONTENT_TOO_LONG ErrorKind = "content_too_long"
+	ERROR_MODEL_NOT_FOUND  ErrorKind = "model_not_found"
+	ERROR_RATE_LIMITED     ErrorKind = "rate_limited"
+	ERROR_UNKNOWN          ErrorKind = "unknown"
+)
+
+var errorHints = map[ErrorKind]string{
+	ERROR_INVALID_KEY:      "the API key was rejected, please update it in the settings",
+	ERROR_QUOTA_EXCEEDED:   "the account has run out of credit, please check the billing of the provider",
+	ERROR_CONTENT_TOO_LONG: "the text is too long for the provider, please shorten the interview or answer",
+	ERROR_MODEL_NOT_FOUND:  "the selected model or voice is not available, please pick another one in the settings",
+	ERROR_RATE_LIMITED:     "the provider is busy, please wait a moment and try again",
+	ERROR_UNKNOWN:          "the provider returned an unexpected error",
+}
+
+// Error is returned by providers when the api rejects a request, use errors.As to inspect it
+type Error struct {
+	Provider   Name
+	Kind       ErrorKind
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Provider, errorHints[e.Kind])
+
+	if e.Message != "" {
+		return fmt.Sprintf("%s (%d %s)", msg, e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("%s (%d %s)", msg, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// KindFromStatus guesses the kind of error when the body doesn't tell
+func KindFromStatus(statusCode int) ErrorKind {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ERROR_INVALID_KEY
+	case http.StatusPaymentRequired:
+		return ERROR_QUOTA_EXCEEDED
+	case http.StatusNotFound:
+		return ERROR_MODEL_NOT_FOUND
+	case http.StatusRequestEntityTooLarge:
+		return ERROR_CONTENT_TOO_LONG
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return ERROR_RATE_LIMITED
+	default:
+		return ERROR_UNKNOWN
+	}
+}
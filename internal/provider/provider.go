@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"io"
+
+	"github.com/madeindra/interview-app/internal/openai/model"
+)
+
+type Name string
+
+const (
+	PROVIDER_OPENAI     Name = "openai"
+	PROVIDER_ELEVENLABS Name = "elevenlabs"
+	PROVIDER_WHISPERCPP Name = "whispercpp"
+	PROVIDER_PIPER      Name = "piper"
+	PROVIDER_AZURE      Name = "azure"
+	PROVIDER_ANTHROPIC  Name = "anthropic"
+)
+
+type Capability string
+
+const (
+	CAPABILITY_CHAT       Capability = "chat"
+	CAPABILITY_TRANSCRIPT Capability = "transcript"
+	CAPABILITY_SPEECH     Capability = "speech"
+)
+
+// ChatProvider generates the interviewer's reply from the chat history
+type ChatProvider interface {
+	Chat(ctx context.Context, messages []model.ChatMessage) (model.ChatResponse, error)
+}
+
+// ChatStreamer is implemented by chat providers that can stream the reply as it is generated
+type ChatStreamer interface {
+	ChatStream(ctx context.Context, messages []model.ChatMessage, onDelta func(string)) (model.ChatResponse, error)
+}
+
+// Transcriber turns the candidate's recorded answer into text
+type Transcriber interface {
+	Transcribe(ctx context.Context, file io.Reader, filename string) (model.TranscriptResponse, error)
+}
+
+// SpeechSynthesizer turns the interviewer's reply into audio
+type SpeechSynthesizer interface {
+	Speechify(ctx context.Context, text string) (io.ReadCloser, error)
+	IsSpeechAvailable(lang string) bool
+	MaxInputLength() int
+}
+
+// KeyValidator is implemented by providers that can verify their API key
+type KeyValidator interface {
+	IsKeyValid(ctx context.Context) (bool, error)
+}
+
+// StatusReporter is implemented by providers that publish their API status
+type StatusReporter interface {
+	Status(ctx context.Context) (model.Status, error)
+}
+
+// ModelLister is implemented by providers that can list the models offered for a capability
+type ModelLister interface {
+	Models(ctx context.Context, capability Capability) ([]string, error)
+}
+
+// ModelNamer is implemented by providers that can name the model serving a capability,
+// usage is recorded and priced under that name
+type ModelNamer interface {
+	ModelName(capability Capability) string
+}
+
+// SpeechDescriber is implemented by synthesizers that can describe the provider, model and voice they speak with,
+// the same description and text give the same audio so it can be cached
+type SpeechDescriber interface {
+	DescribeSpeech() string
+}
@@ -0,0 +1,192 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	id3HeaderLength  = 10
+	riffHeaderLength = 12
+	chunkHeaderSize  = 8
+)
+
+// join plays the parts back to back, wav parts are merged under a single header
+// while mp3 parts can simply follow each other, parts of different formats can't be joined
+// (a fallback in the middle of a reply may speak wav after mp3)
+func join(parts [][]byte) ([]byte, error) {
+	wav := 0
+	for _, part := range parts {
+		if isWAV(part) {
+			wav++
+		}
+	}
+
+	switch wav {
+	case 0:
+		return joinMP3(parts), nil
+	case len(parts):
+		if audio, ok := joinWAV(parts); ok {
+			return audio, nil
+		}
+
+		return nil, fmt.Errorf("cannot join wav audio of different formats")
+	default:
+		return nil, fmt.Errorf("cannot join wav and mp3 audio")
+	}
+}
+
+// joinMP3 drops the ID3 tag of every part but the first
+// so players don't stop at the metadata in the middle of the stream
+func joinMP3(parts [][]byte) []byte {
+	var buf bytes.Buffer
+	for i, part := range parts {
+		if i > 0 {
+			part = stripID3(part)
+		}
+
+		buf.Write(part)
+	}
+
+	return buf.Bytes()
+}
+
+func stripID3(audio []byte) []byte {
+	if len(audio) < id3HeaderLength || !bytes.HasPrefix(audio, []byte("ID3")) {
+		return audio
+	}
+
+	// the tag size is a 28 bit syncsafe integer that excludes the header and footer
+	size := int(audio[6]&0x7f)<<21 | int(audio[7]&0x7f)<<14 | int(audio[8]&0x7f)<<7 | int(audio[9]&0x7f)
+	size += id3HeaderLength
+
+	if audio[5]&0x10 != 0 {
+		size += id3HeaderLength
+	}
+
+	if size > len(audio) {
+		return audio
+	}
+
+	return audio[size:]
+}
+
+func isWAV(audio []byte) bool {
+	return len(audio) >= riffHeaderLength && bytes.HasPrefix(audio, []byte("RIFF")) && bytes.Equal(audio[8:12], []byte("WAVE"))
+}
+
+// joinWAV concatenates the samples of every part under the format of the first one,
+// it gives up when a part isn't a wav file or uses a different format
+func joinWAV(parts [][]byte) ([]byte, bool) {
+	var format []byte
+	var samples bytes.Buffer
+
+	for _, part := range parts {
+		if !isWAV(part) {
+			return nil, false
+		}
+
+		fmtChunk, data, ok := readWAV(part)
+		if !ok {
+			return nil, false
+		}
+
+		if format == nil {
+			format = fmtChunk
+		} else if !bytes.Equal(format, fmtChunk) {
+			return nil, false
+		}
+
+		samples.Write(data)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+chunkHeaderSize+len(format)+chunkHeaderSize+samples.Len()))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(format)))
+	buf.Write(format)
+
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(samples.Len()))
+	buf.Write(samples.Bytes())
+
+	return buf.Bytes(), true
+}
+
+// readWAV returns the body of the fmt and data chunks
+func readWAV(audio []byte) ([]byte, []byte, bool) {
+	var format, data []byte
+
+	for offset := riffHeaderLength; offset+chunkHeaderSize <= len(audio); {
+		id := string(audio[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(audio[offset+4 : offset+8]))
+		start := offset + chunkHeaderSize
+
+		end := start + size
+		if end > len(audio) {
+			end = len(audio)
+		}
+
+		switch id {
+		case "fmt ":
+			format = audio[start:end]
+		case "data":
+			data = audio[start:end]
+		}
+
+		// chunks are padded to an even size
+		offset = start + size + size%2
+	}
+
+	return format, data, format != nil && data != nil
+}
+
+// Duration is the length in seconds of a wav, ogg or webm recording, zero for any other format
+// or when it can't be read
+func Duration(audio []byte) float64 {
+	switch Format(audio) {
+	case "wav":
+		return wavDuration(audio)
+	case "ogg":
+		return oggDuration(audio)
+	case "webm":
+		return webmDuration(audio)
+	default:
+		return 0
+	}
+}
+
+func wavDuration(audio []byte) float64 {
+	format, data, ok := readWAV(audio)
+	// the byte rate sits after the audio format, channel count and sample rate
+	if !ok || len(format) < 12 {
+		return 0
+	}
+
+	byteRate := binary.LittleEndian.Uint32(format[8:12])
+	if byteRate == 0 {
+		return 0
+	}
+
+	return float64(len(data)) / float64(byteRate)
+}
+
+// Format names the container of the audio from its first bytes, empty when it isn't recognized
+func Format(audio []byte) string {
+	switch {
+	case isWAV(audio):
+		return "wav"
+	case bytes.HasPrefix(audio, []byte("ID3")), len(audio) >= 2 && audio[0] == 0xff && audio[1]&0xe0 == 0xe0:
+		return "mp3"
+	case bytes.HasPrefix(audio, []byte("OggS")):
+		return "ogg"
+	case bytes.HasPrefix(audio, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		return "webm"
+	default:
+		return ""
+	}
+}
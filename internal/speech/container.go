@@ -0,0 +1,200 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+const (
+	oggPageHeaderLength = 27
+	opusSampleRate      = 48000
+
+	webmSegment       = 0x18538067
+	webmInfo          = 0x1549a966
+	webmTimecodeScale = 0x2ad7b1
+	webmInfoDuration  = 0x4489
+	webmCluster       = 0x1f43b675
+	webmTimecode      = 0xe7
+	webmBlockGroup    = 0xa0
+	webmBlock         = 0xa1
+	webmSimpleBlock   = 0xa3
+
+	// webmDefaultTimecodeScale is a millisecond in nanoseconds
+	webmDefaultTimecodeScale = 1000000
+)
+
+// oggDuration reads the granule position of the last page, which counts the samples of an opus or vorbis stream,
+// the sample rate and the samples to skip come from the identification header of the first page
+func oggDuration(audio []byte) float64 {
+	if len(audio) < oggPageHeaderLength {
+		return 0
+	}
+
+	payload := oggPageHeaderLength + int(audio[26])
+	if payload > len(audio) {
+		return 0
+	}
+
+	header := audio[payload:]
+
+	var rate, preSkip float64
+	switch {
+	case bytes.HasPrefix(header, []byte("OpusHead")) && len(header) >= 12:
+		rate = opusSampleRate
+		preSkip = float64(binary.LittleEndian.Uint16(header[10:12]))
+	case bytes.HasPrefix(header, []byte("\x01vorbis")) && len(header) >= 16:
+		rate = float64(binary.LittleEndian.Uint32(header[12:16]))
+	default:
+		return 0
+	}
+
+	if rate == 0 {
+		return 0
+	}
+
+	// a page where no packet ends has no granule position, the one before it is taken instead
+	end := len(audio)
+	for {
+		page := bytes.LastIndex(audio[:end], []byte("OggS"))
+		if page < 0 || page+14 > len(audio) {
+			return 0
+		}
+
+		granule := int64(binary.LittleEndian.Uint64(audio[page+6 : page+14]))
+		if granule >= 0 {
+			return math.Max(0, (float64(granule)-preSkip)/rate)
+		}
+
+		end = page
+	}
+}
+
+// webmDuration reads the duration of the segment info, which browsers leave out while recording,
+// and otherwise takes the time of the last block
+func webmDuration(audio []byte) float64 {
+	scale := uint64(webmDefaultTimecodeScale)
+	var duration, cluster, last float64
+
+	for pos := 0; pos < len(audio); {
+		id, idLength, ok := readElementID(audio[pos:])
+		if !ok {
+			break
+		}
+
+		size, sizeLength, known := readElementSize(audio[pos+idLength:])
+		if sizeLength == 0 {
+			break
+		}
+
+		start := pos + idLength + sizeLength
+
+		// the elements holding what is read are entered, their size may be unknown while recording
+		switch id {
+		case webmSegment, webmInfo, webmCluster, webmBlockGroup:
+			pos = start
+			continue
+		}
+
+		if !known || size > uint64(len(audio)-start) {
+			break
+		}
+
+		body := audio[start : start+int(size)]
+		switch id {
+		case webmTimecodeScale:
+			if value := readUint(body); value > 0 {
+				scale = value
+			}
+		case webmInfoDuration:
+			duration = readFloat(body)
+		case webmTimecode:
+			cluster = float64(readUint(body))
+		case webmBlock, webmSimpleBlock:
+			// the block starts with its track number and its time relative to the cluster
+			_, trackLength, _ := readElementSize(body)
+			if trackLength > 0 && len(body) >= trackLength+2 {
+				offset := int16(binary.BigEndian.Uint16(body[trackLength : trackLength+2]))
+				last = math.Max(last, cluster+float64(offset))
+			}
+		}
+
+		pos = start + int(size)
+	}
+
+	if duration == 0 {
+		duration = last
+	}
+
+	return duration * float64(scale) / float64(1e9)
+}
+
+// readElementID reads an ebml id, which keeps its length marker
+func readElementID(b []byte) (uint32, int, bool) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0, false
+	}
+
+	length := 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+
+	if length > 4 || len(b) < length {
+		return 0, 0, false
+	}
+
+	var id uint32
+	for _, c := range b[:length] {
+		id = id<<8 | uint32(c)
+	}
+
+	return id, length, true
+}
+
+// readElementSize reads an ebml size, which is unknown when all its bits are set
+func readElementSize(b []byte) (uint64, int, bool) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0, false
+	}
+
+	length := 1
+	mask := byte(0x80)
+	for b[0]&mask == 0 {
+		length++
+		mask >>= 1
+	}
+
+	if len(b) < length {
+		return 0, 0, false
+	}
+
+	size := uint64(b[0] & (mask - 1))
+	for _, c := range b[1:length] {
+		size = size<<8 | uint64(c)
+	}
+
+	unknown := uint64(1)<<(7*length) - 1
+
+	return size, length, size != unknown
+}
+
+func readUint(b []byte) uint64 {
+	var value uint64
+	for _, c := range b {
+		value = value<<8 | uint64(c)
+	}
+
+	return value
+}
+
+func readFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,183 @@
+package speech
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minSentenceLength keeps abbreviations and short interjections from becoming their own request
+const minSentenceLength = 20
+
+type Chunk struct {
+	Index int
+	Text  string
+	Audio []byte
+}
+
+// Pipeline splits streamed text into sentences and synthesizes them concurrently,
+// handing the audio chunks to onChunk in the same order as the sentences
+type Pipeline struct {
+	synthesize func(string) ([]byte, error)
+	onChunk    func(Chunk)
+
+	buf  strings.Builder
+	next int
+	sem  chan struct{}
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[int]Chunk
+	emitted int
+	chunks  []Chunk
+	err     error
+}
+
+func NewPipeline(concurrency int, synthesize func(string) ([]byte, error), onChunk func(Chunk)) *Pipeline {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Pipeline{
+		synthesize: synthesize,
+		onChunk:    onChunk,
+		sem:        make(chan struct{}, concurrency),
+		pending:    map[int]Chunk{},
+	}
+}
+
+// Write appends streamed text and dispatches every sentence completed by it
+func (p *Pipeline) Write(text string) {
+	p.buf.WriteString(text)
+
+	for {
+		sentence, rest, ok := splitSentence(p.buf.String())
+		if !ok {
+			return
+		}
+
+		p.buf.Reset()
+		p.buf.WriteString(rest)
+		p.dispatch(sentence)
+	}
+}
+
+// Close dispatches the remaining text, waits for every sentence and returns the chunks in order
+func (p *Pipeline) Close() ([]Chunk, error) {
+	p.dispatch(p.buf.String())
+	p.buf.Reset()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.chunks, p.err
+}
+
+func (p *Pipeline) dispatch(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" || p.failed() {
+		return
+	}
+
+	index := p.next
+	p.next++
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		p.sem <- struct{}{}
+		defer func() { <-p.sem }()
+
+		audio, err := p.synthesize(text)
+		p.done(Chunk{Index: index, Text: text, Audio: audio}, err)
+	}()
+}
+
+func (p *Pipeline) done(chunk Chunk, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+
+	p.pending[chunk.Index] = chunk
+
+	// release every chunk that is next in line, stop emitting once a sentence failed
+	for {
+		next, ok := p.pending[p.emitted]
+		if !ok {
+			return
+		}
+
+		delete(p.pending, p.emitted)
+		p.emitted++
+
+		if p.err != nil {
+			continue
+		}
+
+		p.chunks = append(p.chunks, next)
+		if p.onChunk != nil {
+			p.onChunk(next)
+		}
+	}
+}
+
+func (p *Pipeline) failed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err != nil
+}
+
+// Concat joins the audio of every chunk into a single file, failing when the chunks are of different formats
+func Concat(chunks []Chunk) ([]byte, error) {
+	audios := make([][]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		audios = append(audios, chunk.Audio)
+	}
+
+	return join(audios)
+}
+
+// splitSentence returns the first sentence long enough to be synthesized on its own,
+// a terminator only counts once the following whitespace has been received
+func splitSentence(text string) (string, string, bool) {
+	for i, r := range text {
+		if !isTerminator(r) {
+			continue
+		}
+
+		end := i + utf8.RuneLen(r)
+		if end >= len(text) {
+			return "", "", false
+		}
+
+		next, _ := utf8.DecodeRuneInString(text[end:])
+		if !unicode.IsSpace(next) {
+			continue
+		}
+
+		if len(strings.TrimSpace(text[:end])) < minSentenceLength {
+			continue
+		}
+
+		return text[:end], text[end:], true
+	}
+
+	return "", "", false
+}
+
+func isTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…':
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+// Synthesize speaks text of any length by splitting it under the provider's input limit
+// and joining the audio of every part into a single file
+func Synthesize(ctx context.Context, synthesizer provider.SpeechSynthesizer, text string) ([]byte, error) {
+	parts := Split(text, synthesizer.MaxInputLength())
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("nothing to synthesize")
+	}
+
+	audios := make([][]byte, 0, len(parts))
+	for i, part := range parts {
+		audio, err := synthesizePart(ctx, synthesizer, part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesize part %d of %d: %w", i+1, len(parts), err)
+		}
+
+		audios = append(audios, audio)
+	}
+
+	return join(audios)
+}
+
+func synthesizePart(ctx context.Context, synthesizer provider.SpeechSynthesizer, text string) ([]byte, error) {
+	audio, err := synthesizer.Speechify(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	defer audio.Close()
+
+	return io.ReadAll(audio)
+}
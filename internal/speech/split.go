@@ -0,0 +1,89 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Split packs the sentences of text into parts of at most limit characters,
+// a sentence longer than the limit is cut at the last space that fits
+func Split(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var current string
+
+	for _, sentence := range sentences(text) {
+		for _, piece := range splitLong(sentence, limit) {
+			if current == "" {
+				current = piece
+				continue
+			}
+
+			if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(piece) <= limit {
+				current += " " + piece
+				continue
+			}
+
+			parts = append(parts, current)
+			current = piece
+		}
+	}
+
+	if current != "" {
+		parts = append(parts, current)
+	}
+
+	return parts
+}
+
+func sentences(text string) []string {
+	var result []string
+	for {
+		sentence, rest, ok := splitSentence(text)
+		if !ok {
+			break
+		}
+
+		result = append(result, strings.TrimSpace(sentence))
+		text = rest
+	}
+
+	if text = strings.TrimSpace(text); text != "" {
+		result = append(result, text)
+	}
+
+	return result
+}
+
+func splitLong(text string, limit int) []string {
+	var parts []string
+	for utf8.RuneCountInString(text) > limit {
+		runes := []rune(text)
+
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+
+		parts = append(parts, strings.TrimSpace(string(runes[:cut])))
+		text = strings.TrimSpace(string(runes[cut:]))
+	}
+
+	if text != "" {
+		parts = append(parts, text)
+	}
+
+	return parts
+}
@@ -0,0 +1,127 @@
+package whisper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/speech"
+)
+
+func (w *Whisper) Transcribe(ctx context.Context, file io.Reader, filename string) (model.TranscriptResponse, error) {
+	if file == nil {
+		log.Default().Println("audio is nil")
+
+		return model.TranscriptResponse{}, fmt.Errorf("audio is nil")
+	}
+
+	dir, err := os.MkdirTemp("", "whisper-*")
+	if err != nil {
+		log.Default().Println("error creating temp dir", err)
+
+		return model.TranscriptResponse{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, filepath.Base(filename))
+	if err := writeFile(input, file); err != nil {
+		log.Default().Println("error writing audio file", err)
+
+		return model.TranscriptResponse{}, err
+	}
+
+	wav, err := w.convert(ctx, dir, input)
+	if err != nil {
+		log.Default().Println("error converting audio", err)
+
+		return model.TranscriptResponse{}, err
+	}
+
+	output := filepath.Join(dir, "transcript")
+	cmd := exec.CommandContext(ctx, w.binary,
+		"-m", w.model,
+		"-f", wav,
+		"-l", w.language,
+		"-nt",
+		"-otxt",
+		"-of", output,
+	)
+
+	if err := run(cmd); err != nil {
+		log.Default().Println("error running whisper.cpp", err)
+
+		return model.TranscriptResponse{}, err
+	}
+
+	text, err := os.ReadFile(output + ".txt")
+	if err != nil {
+		log.Default().Println("error reading transcript", err)
+
+		return model.TranscriptResponse{}, err
+	}
+
+	// the duration is only known when the recording is a wav, which it is once converted
+	var duration float64
+	if audio, err := os.ReadFile(wav); err == nil {
+		duration = speech.Duration(audio)
+	}
+
+	return model.TranscriptResponse{Text: strings.TrimSpace(string(text)), Duration: duration}, nil
+}
+
+// convert resamples the recording to the 16kHz mono wav whisper.cpp expects,
+// the recording is passed as is when ffmpeg isn't installed
+func (w *Whisper) convert(ctx context.Context, dir, input string) (string, error) {
+	ffmpeg, err := exec.LookPath(w.ffmpeg)
+	if err != nil {
+		log.Default().Println("ffmpeg not found, passing audio as is", err)
+
+		return input, nil
+	}
+
+	output := filepath.Join(dir, "audio.16k.wav")
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-y",
+		"-i", input,
+		"-ar", sampleRate,
+		"-ac", "1",
+		"-c:a", "pcm_s16le",
+		output,
+	)
+
+	if err := run(cmd); err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// run executes the command and includes its stderr in the error
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %v: %s", filepath.Base(cmd.Path), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
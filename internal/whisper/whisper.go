@@ -0,0 +1,76 @@
+package whisper
+
+import (
+	"path/filepath"
+
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+type Whisper struct {
+	binary   string
+	model    string
+	ffmpeg   string
+	language string
+}
+
+const (
+	defaultFFmpeg   = "ffmpeg"
+	defaultLanguage = "en"
+	sampleRate      = "16000"
+)
+
+type Option func(*Whisper)
+
+// New creates a transcriber that runs the whisper.cpp executable with a local ggml model
+func New(binary, model string, opts ...Option) *Whisper {
+	w := &Whisper{
+		binary:   binary,
+		model:    model,
+		ffmpeg:   defaultFFmpeg,
+		language: defaultLanguage,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// WithFFmpeg sets the ffmpeg executable used to convert recordings to 16kHz wav, an empty path keeps the default
+func WithFFmpeg(path string) Option {
+	return func(w *Whisper) {
+		if path != "" {
+			w.ffmpeg = path
+		}
+	}
+}
+
+// WithLanguage sets the spoken language, an empty language keeps the default
+func WithLanguage(lang string) Option {
+	return func(w *Whisper) {
+		if lang != "" {
+			w.language = lang
+		}
+	}
+}
+
+// Clone copies the transcriber with the options applied, such as the language of a single interview
+func (w *Whisper) Clone(opts ...Option) *Whisper {
+	clone := *w
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+
+	return &clone
+}
+
+// ModelName is the file name of the ggml model, only transcription is served
+func (w *Whisper) ModelName(capability provider.Capability) string {
+	if capability != provider.CAPABILITY_TRANSCRIPT {
+		return ""
+	}
+
+	return filepath.Base(w.model)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"flag"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -14,8 +15,11 @@ var assets embed.FS
 var icon []byte
 
 func main() {
+	dbPath := flag.String("db", "", "path of the database, defaults to the user's config directory")
+	flag.Parse()
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(*dbPath)
 
 	// Create application with options
 	err := wails.Run(&options.App{
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+// modelCacheTTL keeps the settings from calling the models endpoint on every render
+const modelCacheTTL = 10 * time.Minute
+
+// modelCache holds the model lists of the loaded providers, it is replaced with them
+type modelCache struct {
+	mu      sync.Mutex
+	entries map[provider.Capability]cachedModels
+}
+
+type cachedModels struct {
+	models    []string
+	expiresAt time.Time
+}
+
+func newModelCache() *modelCache {
+	return &modelCache{entries: map[provider.Capability]cachedModels{}}
+}
+
+// lister returns the provider serving the capability when it can list its models
+func (p providers) lister(capability provider.Capability) (provider.ModelLister, error) {
+	var current any
+
+	switch capability {
+	case provider.CAPABILITY_CHAT:
+		current = p.chat.primary()
+	case provider.CAPABILITY_TRANSCRIPT:
+		current = p.transcriber.primary()
+	case provider.CAPABILITY_SPEECH:
+		current = p.speech.primary()
+	default:
+		return nil, fmt.Errorf("unsupported capability: %s", capability)
+	}
+
+	lister, ok := current.(provider.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider can't list its models", capability)
+	}
+
+	return lister, nil
+}
+
+// listModels returns the cached model list of the capability, fetching it when missing or expired
+func (p providers) listModels(ctx context.Context, capability provider.Capability) ([]string, error) {
+	lister, err := p.lister(capability)
+	if err != nil {
+		return nil, err
+	}
+
+	p.models.mu.Lock()
+	defer p.models.mu.Unlock()
+
+	if cached, ok := p.models.entries[capability]; ok && time.Now().Before(cached.expiresAt) {
+		return cached.models, nil
+	}
+
+	models, err := lister.Models(ctx, capability)
+	if err != nil {
+		return nil, err
+	}
+
+	p.models.entries[capability] = cachedModels{
+		models:    models,
+		expiresAt: time.Now().Add(modelCacheTTL),
+	}
+
+	return models, nil
+}
+
+// checkModels makes sure the models saved in the settings are still offered by the providers,
+// providers that can't list their models or can't be reached are given the benefit of the doubt.
+// Only the official apis are held to their list, other servers accept aliases they don't list
+// (ollama lists llama3:latest but serves llama3), so a missing model there is only logged
+func checkModels(ctx context.Context, p providers, setting model.Setting) error {
+	saved := []struct {
+		capability provider.Capability
+		baseURL    string
+		model      string
+	}{
+		{provider.CAPABILITY_CHAT, setting.ChatBaseURL, setting.ChatModel},
+		{provider.CAPABILITY_TRANSCRIPT, setting.TranscriptBaseURL, setting.TranscriptModel},
+		{provider.CAPABILITY_SPEECH, setting.SpeechBaseURL, setting.SpeechModel},
+	}
+
+	for _, s := range saved {
+		// an empty model is the provider's default
+		if s.model == "" {
+			continue
+		}
+
+		if _, err := p.lister(s.capability); err != nil {
+			continue
+		}
+
+		models, err := p.listModels(ctx, s.capability)
+		if err != nil {
+			continue
+		}
+
+		if isListed(models, s.model) {
+			continue
+		}
+
+		if s.baseURL != "" {
+			log.Default().Printf("the %s model %s is not listed by %s, it may be an alias", s.capability, s.model, s.baseURL)
+			continue
+		}
+
+		return fmt.Errorf("the %s model %s is no longer available, please pick another one in the settings", s.capability, s.model)
+	}
+
+	return nil
+}
+
+// isListed is true when the model is listed, or when it is a -latest alias (anthropic only lists dated ids,
+// claude-3-5-haiku-latest is served by claude-3-5-haiku-20241022) of a listed model
+func isListed(models []string, name string) bool {
+	if slices.Contains(models, name) {
+		return true
+	}
+
+	family, ok := strings.CutSuffix(name, "-latest")
+	if !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(models, func(m string) bool {
+		return strings.HasPrefix(m, family+"-")
+	})
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+
+	"github.com/madeindra/interview-app/internal/anthropic"
+	"github.com/madeindra/interview-app/internal/audiocache"
+	"github.com/madeindra/interview-app/internal/breaker"
+	"github.com/madeindra/interview-app/internal/elevenlabs"
+	elModel "github.com/madeindra/interview-app/internal/elevenlabs/model"
+	"github.com/madeindra/interview-app/internal/httpclient"
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/openai"
+	oaiModel "github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/piper"
+	"github.com/madeindra/interview-app/internal/provider"
+	"github.com/madeindra/interview-app/internal/whisper"
+)
+
+// maxRetries keeps a failing request from holding the interview for too long
+const maxRetries = 10
+
+// providers chain every capability to its fallbacks, the breakers are closed again whenever the providers are reloaded
+type providers struct {
+	chat        chain[provider.ChatProvider]
+	transcriber chain[provider.Transcriber]
+	speech      chain[provider.SpeechSynthesizer]
+	// coverage speaks the languages no provider of the speech chain can, it is never a fallback
+	coverage chain[provider.SpeechSynthesizer]
+	breakers *breaker.Group
+	models   *modelCache
+	voices   *voiceCache
+	audio    *audiocache.Cache
+}
+
+// loadProviders builds the chat, transcription and speech providers from the saved settings
+func (a *App) loadProviders() error {
+	setting, err := a.model.GetSetting()
+	if err != nil {
+		return fmt.Errorf("failed to get setting: %v", err)
+	}
+
+	p, err := newProviders(setting)
+	if err != nil {
+		return err
+	}
+
+	p.audio = a.audioCache(setting)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.providers = p
+
+	return nil
+}
+
+func newProviders(setting model.Setting) (providers, error) {
+	chat, err := newChain(setting, provider.CAPABILITY_CHAT, newChatProvider)
+	if err != nil {
+		return providers{}, err
+	}
+
+	transcriber, err := newChain(setting, provider.CAPABILITY_TRANSCRIPT, newTranscriber)
+	if err != nil {
+		return providers{}, err
+	}
+
+	speech, err := newChain(setting, provider.CAPABILITY_SPEECH, newSpeechSynthesizer)
+	if err != nil {
+		return providers{}, err
+	}
+
+	// elevenlabs covers the languages the speech chain can't speak, a failing chain is never sent to it
+	var coverage chain[provider.SpeechSynthesizer]
+	if !speech.has(provider.PROVIDER_ELEVENLABS) {
+		el, err := newSpeechSynthesizer(fallbackSetting(setting, provider.CAPABILITY_SPEECH, provider.PROVIDER_ELEVENLABS))
+		if err != nil {
+			return providers{}, err
+		}
+
+		coverage = chain[provider.SpeechSynthesizer]{{name: provider.PROVIDER_ELEVENLABS, provider: el}}
+	}
+
+	p := providers{
+		chat:        chat,
+		transcriber: transcriber,
+		speech:      speech,
+		coverage:    coverage,
+		breakers:    breaker.NewGroup(breakerThreshold, breakerCooldown),
+		models:      newModelCache(),
+		voices:      &voiceCache{},
+	}
+
+	return p, nil
+}
+
+func (a *App) getProviders() (providers, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.providers.chat) == 0 || len(a.providers.transcriber) == 0 {
+		return providers{}, fmt.Errorf("providers are not configured")
+	}
+
+	return a.providers, nil
+}
+
+// completeChat completes the chat with the first provider of the chain that succeeds and returns it,
+// a provider that fails after streaming part of the reply ends the chain as the reply can't be taken back
+func (a *App) completeChat(ctx context.Context, p providers, userID string, messages []oaiModel.ChatMessage, onDelta func(string)) (oaiModel.ChatResponse, provider.ChatProvider, error) {
+	chatResp, served, err := tryChain(ctx, p.breakers, provider.CAPABILITY_CHAT, p.chat, chatTimeout, func(ctx context.Context, chat provider.ChatProvider) (oaiModel.ChatResponse, error) {
+		return a.completeWith(ctx, chat, userID, messages, onDelta)
+	})
+	if err != nil {
+		return oaiModel.ChatResponse{}, nil, err
+	}
+
+	a.emitServed(userID, provider.CAPABILITY_CHAT, p.chat[0].name, served.name)
+
+	return chatResp, served.provider, nil
+}
+
+// completeWith streams the completion to the frontend when the provider supports it,
+// onDelta receives the reply as it is generated or at once when the provider can't stream
+func (a *App) completeWith(ctx context.Context, chat provider.ChatProvider, userID string, messages []oaiModel.ChatMessage, onDelta func(string)) (oaiModel.ChatResponse, error) {
+	streamer, ok := chat.(provider.ChatStreamer)
+	if !ok {
+		chatResp, err := chat.Chat(ctx, messages)
+		if err == nil && onDelta != nil && len(chatResp.Choices) > 0 {
+			onDelta(chatResp.Choices[0].Message.Content)
+		}
+
+		return chatResp, err
+	}
+
+	streamed := false
+	chatResp, err := streamer.ChatStream(ctx, messages, func(delta string) {
+		streamed = true
+		a.emit(EVENT_CHAT_DELTA, model.ChatDeltaEvent{ID: userID, Delta: delta})
+
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	})
+	if err != nil && streamed {
+		return chatResp, &partialError{err: err}
+	}
+
+	return chatResp, err
+}
+
+// newHTTPClient retries failed requests as many times as the settings allow
+func newHTTPClient(setting model.Setting) *http.Client {
+	config := httpclient.DefaultConfig
+	config.MaxRetries = setting.MaxRetries
+
+	return httpclient.New(config)
+}
+
+func newChatProvider(setting model.Setting) (provider.ChatProvider, error) {
+	switch provider.Name(setting.ChatProvider) {
+	case provider.PROVIDER_OPENAI:
+		return openai.New(setting.OpenAIKey,
+			openai.WithBaseURL(setting.ChatBaseURL),
+			openai.WithChatModel(setting.ChatModel),
+			openai.WithGeneration(oaiModel.Generation{
+				Temperature: setting.ChatTemperature,
+				MaxTokens:   setting.ChatMaxTokens,
+				TopP:        setting.ChatTopP,
+			}),
+			openai.WithNoAuth(setting.ChatNoAuth),
+			openai.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_AZURE:
+		return openai.New(setting.AzureKey,
+			openai.WithAzure(setting.AzureAPIVersion),
+			openai.WithBaseURL(setting.ChatBaseURL),
+			openai.WithChatModel(setting.ChatModel),
+			openai.WithGeneration(oaiModel.Generation{
+				Temperature: setting.ChatTemperature,
+				MaxTokens:   setting.ChatMaxTokens,
+				TopP:        setting.ChatTopP,
+			}),
+			openai.WithNoAuth(setting.ChatNoAuth),
+			openai.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_ANTHROPIC:
+		return anthropic.New(setting.AnthropicKey,
+			anthropic.WithBaseURL(setting.ChatBaseURL),
+			anthropic.WithChatModel(setting.ChatModel),
+			anthropic.WithGeneration(setting.ChatTemperature, setting.ChatMaxTokens, setting.ChatTopP),
+			anthropic.WithNoAuth(setting.ChatNoAuth),
+			anthropic.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported chat provider: %s", setting.ChatProvider)
+	}
+}
+
+func newTranscriber(setting model.Setting) (provider.Transcriber, error) {
+	switch provider.Name(setting.TranscriptProvider) {
+	case provider.PROVIDER_OPENAI:
+		return openai.New(setting.OpenAIKey,
+			openai.WithBaseURL(setting.TranscriptBaseURL),
+			openai.WithTranscriptModel(setting.TranscriptModel),
+			openai.WithNoAuth(setting.TranscriptNoAuth),
+			openai.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_AZURE:
+		return openai.New(setting.AzureKey,
+			openai.WithAzure(setting.AzureAPIVersion),
+			openai.WithBaseURL(setting.TranscriptBaseURL),
+			openai.WithTranscriptModel(setting.TranscriptModel),
+			openai.WithNoAuth(setting.TranscriptNoAuth),
+			openai.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_WHISPERCPP:
+		if setting.WhisperBinary == "" || setting.WhisperModel == "" {
+			return nil, fmt.Errorf("whisper.cpp binary and model are required")
+		}
+
+		return whisper.New(setting.WhisperBinary, setting.WhisperModel,
+			whisper.WithFFmpeg(setting.FFmpegBinary),
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported transcript provider: %s", setting.TranscriptProvider)
+	}
+}
+
+func newSpeechSynthesizer(setting model.Setting) (provider.SpeechSynthesizer, error) {
+	switch provider.Name(setting.SpeechProvider) {
+	case provider.PROVIDER_OPENAI:
+		return openai.New(setting.OpenAIKey,
+			openai.WithBaseURL(setting.SpeechBaseURL),
+			openai.WithTTSModel(setting.SpeechModel),
+			openai.WithTTSVoice(setting.SpeechVoice),
+			openai.WithNoAuth(setting.SpeechNoAuth),
+			openai.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_AZURE:
+		return openai.New(setting.AzureKey,
+			openai.WithAzure(setting.AzureAPIVersion),
+			openai.WithBaseURL(setting.SpeechBaseURL),
+			openai.WithTTSModel(setting.SpeechModel),
+			openai.WithTTSVoice(setting.SpeechVoice),
+			openai.WithNoAuth(setting.SpeechNoAuth),
+			openai.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_ELEVENLABS:
+		return elevenlabs.New(setting.ElevenLabsKey,
+			elevenlabs.WithBaseURL(setting.SpeechBaseURL),
+			elevenlabs.WithTTSModel(setting.SpeechModel),
+			elevenlabs.WithVoice(setting.SpeechVoice),
+			elevenlabs.WithVoiceSetting(elModel.VoiceSetting{
+				Stability:       setting.ElevenLabsStability,
+				SimilarityBoost: setting.ElevenLabsSimilarity,
+			}),
+			elevenlabs.WithNoAuth(setting.SpeechNoAuth),
+			elevenlabs.WithHTTPClient(newHTTPClient(setting)),
+		), nil
+	case provider.PROVIDER_PIPER:
+		if setting.PiperBinary == "" || setting.PiperModel == "" {
+			return nil, fmt.Errorf("piper binary and voice model are required")
+		}
+
+		return piper.New(setting.PiperBinary, setting.PiperModel), nil
+	default:
+		return nil, fmt.Errorf("unsupported speech provider: %s", setting.SpeechProvider)
+	}
+}
+
+func validateSetting(setting model.Setting) error {
+	if setting.MaxRetries < 0 || setting.MaxRetries > maxRetries {
+		return fmt.Errorf("max retries must be between 0 and %d", maxRetries)
+	}
+
+	if err := validateGeneration(setting); err != nil {
+		return err
+	}
+
+	if err := validateBudget(setting); err != nil {
+		return err
+	}
+
+	if setting.SpeechCacheSize < 0 {
+		return fmt.Errorf("speech cache size can't be negative")
+	}
+
+	if err := validateVoice(setting); err != nil {
+		return err
+	}
+
+	for _, baseURL := range []string{setting.ChatBaseURL, setting.TranscriptBaseURL, setting.SpeechBaseURL} {
+		if err := validateBaseURL(baseURL); err != nil {
+			return err
+		}
+	}
+
+	if err := validateAzure(setting); err != nil {
+		return err
+	}
+
+	if err := validateFallbacks(setting); err != nil {
+		return err
+	}
+
+	if usesProvider(setting, provider.CAPABILITY_TRANSCRIPT, provider.PROVIDER_WHISPERCPP) {
+		if _, err := os.Stat(setting.WhisperModel); err != nil {
+			return fmt.Errorf("invalid whisper.cpp model: %v", err)
+		}
+
+		if _, err := exec.LookPath(setting.WhisperBinary); err != nil {
+			return fmt.Errorf("invalid whisper.cpp binary: %v", err)
+		}
+	}
+
+	if usesProvider(setting, provider.CAPABILITY_SPEECH, provider.PROVIDER_PIPER) {
+		if _, err := os.Stat(setting.PiperModel); err != nil {
+			return fmt.Errorf("invalid piper voice model: %v", err)
+		}
+
+		if _, err := exec.LookPath(setting.PiperBinary); err != nil {
+			return fmt.Errorf("invalid piper binary: %v", err)
+		}
+	}
+
+	// building the providers rejects unsupported provider names
+	if _, err := newProviders(setting); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateFallbacks rejects fallbacks repeating a provider of the chain, and azure,
+// whose deployments are named by the model of the configured provider
+func validateFallbacks(setting model.Setting) error {
+	for _, capability := range []provider.Capability{provider.CAPABILITY_CHAT, provider.CAPABILITY_TRANSCRIPT, provider.CAPABILITY_SPEECH} {
+		primary, fallbacks := capabilityProviders(setting, capability)
+		seen := map[provider.Name]bool{primary: true}
+
+		for _, fallback := range fallbacks {
+			name := provider.Name(fallback)
+			if name == provider.PROVIDER_AZURE {
+				return fmt.Errorf("azure can't be a %s fallback", capability)
+			}
+
+			if seen[name] {
+				return fmt.Errorf("%s is already in the %s fallbacks", name, capability)
+			}
+
+			seen[name] = true
+		}
+	}
+
+	return nil
+}
+
+// usesProvider is true when the provider serves the capability or is one of its fallbacks
+func usesProvider(setting model.Setting, capability provider.Capability, name provider.Name) bool {
+	primary, fallbacks := capabilityProviders(setting, capability)
+	if primary == name {
+		return true
+	}
+
+	for _, fallback := range fallbacks {
+		if provider.Name(fallback) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateGeneration checks the generation and voice parameters against the ranges the providers accept
+func validateGeneration(setting model.Setting) error {
+	if t := setting.ChatTemperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+
+	if t, limit := setting.ChatTemperature, maxTemperature(provider.Name(setting.ChatProvider)); t != nil && *t > limit {
+		return fmt.Errorf("temperature must be between 0 and %g for %s", limit, setting.ChatProvider)
+	}
+
+	if p := setting.ChatTopP; p != nil && (*p <= 0 || *p > 1) {
+		return fmt.Errorf("top p must be greater than 0 and at most 1")
+	}
+
+	if n := setting.ChatMaxTokens; n != nil && *n < 1 {
+		return fmt.Errorf("max tokens must be at least 1")
+	}
+
+	if setting.ElevenLabsStability < 0 || setting.ElevenLabsStability > 1 {
+		return fmt.Errorf("voice stability must be between 0 and 1")
+	}
+
+	if setting.ElevenLabsSimilarity < 0 || setting.ElevenLabsSimilarity > 1 {
+		return fmt.Errorf("voice similarity must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// maxTemperature is the highest temperature the chat provider accepts
+func maxTemperature(name provider.Name) float32 {
+	if name == provider.PROVIDER_ANTHROPIC {
+		return 1
+	}
+
+	return 2
+}
+
+// validateVoice only knows the voices of the official openai api, other servers name them freely
+func validateVoice(setting model.Setting) error {
+	if provider.Name(setting.SpeechProvider) != provider.PROVIDER_OPENAI || setting.SpeechBaseURL != "" || setting.SpeechVoice == "" {
+		return nil
+	}
+
+	if !openai.IsVoice(setting.SpeechVoice) {
+		return fmt.Errorf("unsupported openai voice: %s", setting.SpeechVoice)
+	}
+
+	return nil
+}
+
+// validateAzure requires the resource endpoint and the deployment of every capability served by azure
+func validateAzure(setting model.Setting) error {
+	capabilities := []struct {
+		capability provider.Capability
+		provider   string
+		baseURL    string
+		deployment string
+	}{
+		{provider.CAPABILITY_CHAT, setting.ChatProvider, setting.ChatBaseURL, setting.ChatModel},
+		{provider.CAPABILITY_TRANSCRIPT, setting.TranscriptProvider, setting.TranscriptBaseURL, setting.TranscriptModel},
+		{provider.CAPABILITY_SPEECH, setting.SpeechProvider, setting.SpeechBaseURL, setting.SpeechModel},
+	}
+
+	for _, c := range capabilities {
+		if provider.Name(c.provider) != provider.PROVIDER_AZURE {
+			continue
+		}
+
+		if c.baseURL == "" {
+			return fmt.Errorf("the azure endpoint of the %s provider is required", c.capability)
+		}
+
+		if c.deployment == "" {
+			return fmt.Errorf("the azure deployment of the %s provider is required", c.capability)
+		}
+	}
+
+	return nil
+}
+
+// validateBaseURL accepts an empty url, which means the provider's default
+func validateBaseURL(baseURL string) error {
+	if baseURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base url: %s", baseURL)
+	}
+
+	return nil
+}
+
+// hasKeys is true when the chat and transcript providers have their key, local providers and servers
+// without auth run without one, speech doesn't need a key as the frontend speaks with the browser without it
+func hasKeys(setting model.Setting) bool {
+	capabilities := []struct {
+		provider string
+		noAuth   bool
+	}{
+		{setting.ChatProvider, setting.ChatNoAuth},
+		{setting.TranscriptProvider, setting.TranscriptNoAuth},
+	}
+
+	for _, c := range capabilities {
+		if c.noAuth {
+			continue
+		}
+
+		if key, required := providerKey(setting, provider.Name(c.provider)); required && key == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// providerKey is the key the provider is called with, the local providers don't require one
+func providerKey(setting model.Setting, name provider.Name) (string, bool) {
+	switch name {
+	case provider.PROVIDER_OPENAI:
+		return setting.OpenAIKey, true
+	case provider.PROVIDER_AZURE:
+		return setting.AzureKey, true
+	case provider.PROVIDER_ANTHROPIC:
+		return setting.AnthropicKey, true
+	case provider.PROVIDER_ELEVENLABS:
+		return setting.ElevenLabsKey, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+const (
+	// sessionAbandonAfter is how long an interview can go without a chat before it is considered abandoned
+	sessionAbandonAfter = 24 * time.Hour
+
+	// maxSessionPage bounds the sessions returned by a single ListSessions call
+	maxSessionPage = 100
+)
+
+// newSession is the configuration the interview starts with, the providers are those left after the budget
+func newSession(p providers, secret, lang, role string, skills []string, voice model.VoiceOptions) model.ChatUser {
+	session := model.ChatUser{
+		Secret:   secret,
+		Language: lang,
+		Voice:    voice,
+		Role:     role,
+		Skills:   skills,
+	}
+
+	if len(p.chat) > 0 {
+		session.ChatProvider = string(p.chat[0].name)
+		session.ChatModel = modelName(p.chat.primary(), provider.CAPABILITY_CHAT)
+	}
+
+	if len(p.transcriber) > 0 {
+		session.TranscriptProvider = string(p.transcriber[0].name)
+		session.TranscriptModel = modelName(p.transcriber.primary(), provider.CAPABILITY_TRANSCRIPT)
+	}
+
+	if len(p.speech) > 0 {
+		session.SpeechProvider = string(p.speech[0].name)
+		session.SpeechModel = modelName(p.speech.primary(), provider.CAPABILITY_SPEECH)
+	}
+
+	return session
+}
+
+// resumeSession refuses an interview that has ended and brings an abandoned one back
+func (a *App) resumeSession(user *model.ChatUser) error {
+	switch user.Status {
+	case model.SESSION_ENDED:
+		return fmt.Errorf("the interview has ended")
+	case model.SESSION_ABANDONED:
+		if err := a.model.UpdateChatUserStatus(user.ID, model.SESSION_ACTIVE); err != nil {
+			return fmt.Errorf("failed to resume chat: %v", err)
+		}
+
+		user.Status = model.SESSION_ACTIVE
+	}
+
+	return nil
+}
+
+// abandonSessions marks the interviews left active for too long as abandoned
+func (a *App) abandonSessions() {
+	abandoned, err := a.model.AbandonChatUsers(time.Now().Add(-sessionAbandonAfter))
+	if err != nil {
+		log.Default().Println("failed to abandon sessions:", err)
+
+		return
+	}
+
+	if abandoned > 0 {
+		log.Default().Printf("marked %d sessions as abandoned", abandoned)
+	}
+}
+
+func isSessionStatus(status string) bool {
+	switch model.SessionStatus(status) {
+	case model.SESSION_ACTIVE, model.SESSION_ENDED, model.SESSION_ABANDONED:
+		return true
+	default:
+		return false
+	}
+}
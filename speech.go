@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+
+	"github.com/madeindra/interview-app/internal/audiocache"
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/provider"
+	"github.com/madeindra/interview-app/internal/speech"
+)
+
+// speechConcurrency bounds how many sentences are synthesized at the same time
+const speechConcurrency = 3
+
+// newSpeechPipeline synthesizes the reply sentence by sentence while it is generated,
+// emitting each audio chunk to the frontend as soon as the previous ones are out, until the context is done
+func (a *App) newSpeechPipeline(ctx context.Context, synthesizer provider.SpeechSynthesizer, userID string) *speech.Pipeline {
+	synthesize := func(text string) ([]byte, error) {
+		return speech.Synthesize(ctx, synthesizer, sanitizeString(text))
+	}
+
+	onChunk := func(chunk speech.Chunk) {
+		// the turn failed or was cancelled, its reply won't reach the candidate
+		if ctx.Err() != nil {
+			return
+		}
+
+		a.emit(EVENT_SPEECH_CHUNK, model.SpeechChunkEvent{
+			ID:    userID,
+			Index: chunk.Index,
+			Audio: base64.StdEncoding.EncodeToString(chunk.Audio),
+		})
+	}
+
+	return speech.NewPipeline(speechConcurrency, synthesize, onChunk)
+}
+
+// megabyte converts the speech cache size of the settings
+const megabyte = 1 << 20
+
+// audioCache applies the size limit of the settings to the speech cache, nil when the cache is switched off
+func (a *App) audioCache(setting model.Setting) *audiocache.Cache {
+	if a.audio == nil {
+		return nil
+	}
+
+	if err := a.audio.SetLimit(int64(setting.SpeechCacheSize) * megabyte); err != nil {
+		log.Default().Println("failed to evict the speech cache:", err)
+	}
+
+	if !setting.SpeechCache {
+		return nil
+	}
+
+	return a.audio
+}
+
+// meteredSpeech serves the speech from the cache and records the usage of every text that is actually synthesized
+func (a *App) meteredSpeech(p providers, synthesizer provider.SpeechSynthesizer, userID string) provider.SpeechSynthesizer {
+	return audiocache.Wrap(synthesizer, p.audio, func(text string) {
+		a.recordSpeechUsage(userID, synthesizer, text)
+	})
+}
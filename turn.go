@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/openai"
+	oaiModel "github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
+	"github.com/madeindra/interview-app/internal/speech"
+	"github.com/madeindra/interview-app/internal/whisper"
+)
+
+// the transcribe, chat and speech timeouts limit each provider of a chain,
+// so a fallback still has its own time after a provider that hung
+const (
+	statusTimeout     = 15 * time.Second
+	transcribeTimeout = 90 * time.Second
+	chatTimeout       = 2 * time.Minute
+	speechTimeout     = 3 * time.Minute
+)
+
+var errTurnCancelled = errors.New("the turn was cancelled")
+
+// turn is the transcription, completion and speech of one answer, it can be cancelled from the frontend
+type turn struct {
+	cancel context.CancelFunc
+}
+
+// baseContext is the wails context, which is cancelled when the app shuts down
+func (a *App) baseContext() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+
+	return a.ctx
+}
+
+// beginTurn cancels any turn still running for the session and returns the context of the new one,
+// the returned func must be called once the turn is over
+func (a *App) beginTurn(userID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(a.baseContext())
+	t := &turn{cancel: cancel}
+
+	a.turnMu.Lock()
+	if previous, ok := a.turns[userID]; ok {
+		previous.cancel()
+	}
+
+	if a.turns == nil {
+		a.turns = map[string]*turn{}
+	}
+
+	a.turns[userID] = t
+	a.turnMu.Unlock()
+
+	return ctx, func() {
+		a.turnMu.Lock()
+		if a.turns[userID] == t {
+			delete(a.turns, userID)
+		}
+		a.turnMu.Unlock()
+
+		cancel()
+	}
+}
+
+func (a *App) cancelTurn(userID string) {
+	a.turnMu.Lock()
+	defer a.turnMu.Unlock()
+
+	if t, ok := a.turns[userID]; ok {
+		t.cancel()
+	}
+}
+
+// stageError reports a cancelled turn plainly instead of the error of the stage it interrupted
+func stageError(ctx context.Context, stage string, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return errTurnCancelled
+	}
+
+	return fmt.Errorf("failed to %s: %w", stage, err)
+}
+
+// transcribe transcribes the answer with the first transcriber of the chain that succeeds and returns it
+func (a *App) transcribe(ctx context.Context, p providers, userID, lang string, audioData []byte) (oaiModel.TranscriptResponse, provider.Transcriber, error) {
+	// the browser records webm or ogg, the extension tells the providers which
+	format := speech.Format(audioData)
+	if format == "" {
+		format = "wav"
+	}
+
+	transcript, served, err := tryChain(ctx, p.breakers, provider.CAPABILITY_TRANSCRIPT, p.transcriber, transcribeTimeout, func(ctx context.Context, transcriber provider.Transcriber) (oaiModel.TranscriptResponse, error) {
+		return sessionTranscriber(transcriber, lang).Transcribe(ctx, bytes.NewReader(audioData), "audio."+format)
+	})
+	if err != nil {
+		return oaiModel.TranscriptResponse{}, nil, err
+	}
+
+	// the length of the recording is read from it when the provider doesn't report it
+	if transcript.Seconds() == 0 {
+		transcript.Duration = speech.Duration(audioData)
+	}
+
+	a.emitServed(userID, provider.CAPABILITY_TRANSCRIPT, p.transcriber[0].name, served.name)
+
+	return transcript, served.provider, nil
+}
+
+// sessionTranscriber listens for the language of the interview rather than the default english
+func sessionTranscriber(transcriber provider.Transcriber, lang string) provider.Transcriber {
+	switch t := transcriber.(type) {
+	case *openai.OpenAI:
+		return t.Clone(openai.WithTranscriptLanguage(lang))
+	case *whisper.Whisper:
+		return t.Clone(whisper.WithLanguage(lang))
+	default:
+		return transcriber
+	}
+}
+
+// reply completes the chat and synthesizes it sentence by sentence while it is generated, returning the audio
+// to be stored, a failed synthesis is reported on the reply so the text still reaches the candidate
+func (a *App) reply(ctx context.Context, p providers, user *model.ChatUser, messages []oaiModel.ChatMessage) (model.Chat, []byte, error) {
+	speechCtx, cancelSpeech := context.WithCancel(ctx)
+	defer cancelSpeech()
+
+	var pipeline *speech.Pipeline
+	var onDelta func(string)
+	synthesizer := a.speechFor(p, user.ID, user.Language, user.Voice)
+	if synthesizer != nil {
+		pipeline = a.newSpeechPipeline(speechCtx, synthesizer, user.ID)
+		onDelta = pipeline.Write
+	}
+
+	// a failed completion drops the speech of the part that was streamed, waiting for the synthesis to stop
+	abandonSpeech := func() {
+		cancelSpeech()
+		if pipeline != nil {
+			pipeline.Close()
+		}
+	}
+
+	chatCompletion, chat, err := a.completeChat(ctx, p, user.ID, messages, onDelta)
+	if err != nil {
+		abandonSpeech()
+		return model.Chat{}, nil, stageError(ctx, "get chat completion", err)
+	}
+
+	a.recordChatUsage(user.ID, chat, chatCompletion)
+
+	if len(chatCompletion.Choices) == 0 {
+		abandonSpeech()
+		return model.Chat{}, nil, fmt.Errorf("cannot complete chat completion: no chat completion")
+	}
+
+	reply := model.Chat{
+		Text: chatCompletion.Choices[0].Message.Content,
+	}
+
+	var audio []byte
+	if pipeline != nil {
+		chunks, err := pipeline.Close()
+		if ctx.Err() != nil {
+			return model.Chat{}, nil, stageError(ctx, "synthesize speech", ctx.Err())
+		}
+
+		if err == nil {
+			// the chunks were already played, only the stored reply is lost when they can't be joined
+			audio, err = speech.Concat(chunks)
+		}
+
+		if err != nil {
+			log.Default().Println("failed to synthesize speech:", err)
+			reply.SpeechError = fmt.Sprintf("failed to synthesize speech: %v", err)
+			audio = nil
+		} else {
+			reply.Audio = base64.StdEncoding.EncodeToString(audio)
+		}
+	}
+
+	return reply, audio, nil
+}
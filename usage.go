@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/madeindra/interview-app/internal/model"
+	oaiModel "github.com/madeindra/interview-app/internal/openai/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+const (
+	PERIOD_DAY   = "day"
+	PERIOD_WEEK  = "week"
+	PERIOD_MONTH = "month"
+	PERIOD_ALL   = "all"
+)
+
+// recordUsage stores what a stage consumed, a failure is only logged so it never fails the turn
+func (a *App) recordUsage(record model.UsageRecord) {
+	if err := a.model.CreateUsage(record); err != nil {
+		log.Default().Println("failed to record usage:", err)
+	}
+}
+
+func (a *App) recordChatUsage(userID string, chat provider.ChatProvider, chatResp oaiModel.ChatResponse) {
+	// the response names the exact model version, which the prices are keyed by
+	chatModel := chatResp.Model
+	if chatModel == "" {
+		chatModel = modelName(chat, provider.CAPABILITY_CHAT)
+	}
+
+	a.recordUsage(model.UsageRecord{
+		ChatUserID:   userID,
+		Capability:   string(provider.CAPABILITY_CHAT),
+		Model:        chatModel,
+		InputTokens:  chatResp.Usage.PromptTokens,
+		OutputTokens: chatResp.Usage.CompletionTokens,
+	})
+}
+
+func (a *App) recordTranscriptUsage(userID string, transcriber provider.Transcriber, transcript oaiModel.TranscriptResponse) {
+	record := model.UsageRecord{
+		ChatUserID:   userID,
+		Capability:   string(provider.CAPABILITY_TRANSCRIPT),
+		Model:        modelName(transcriber, provider.CAPABILITY_TRANSCRIPT),
+		AudioSeconds: transcript.Seconds(),
+	}
+
+	if transcript.Usage != nil {
+		record.InputTokens = transcript.Usage.InputTokens
+		record.OutputTokens = transcript.Usage.OutputTokens
+	}
+
+	a.recordUsage(record)
+}
+
+func (a *App) recordSpeechUsage(userID string, synthesizer provider.SpeechSynthesizer, text string) {
+	a.recordUsage(model.UsageRecord{
+		ChatUserID: userID,
+		Capability: string(provider.CAPABILITY_SPEECH),
+		Model:      modelName(synthesizer, provider.CAPABILITY_SPEECH),
+		Characters: utf8.RuneCountInString(text),
+	})
+}
+
+// modelName is the model the provider serves the capability with, empty when it can't tell
+func modelName(p any, capability provider.Capability) string {
+	namer, ok := p.(provider.ModelNamer)
+	if !ok {
+		return ""
+	}
+
+	return namer.ModelName(capability)
+}
+
+// periodStart is the local time the period began at, a zero time for all time
+func periodStart(period string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case PERIOD_DAY:
+		return today, nil
+	case PERIOD_WEEK:
+		// weeks start on monday
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), nil
+	case PERIOD_MONTH:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	case PERIOD_ALL, "":
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported period: %s", period)
+	}
+}
+
+func validatePrice(price model.Price) error {
+	if price.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+
+	if price.InputPrice < 0 || price.OutputPrice < 0 || price.AudioPrice < 0 || price.CharacterPrice < 0 {
+		return fmt.Errorf("prices can't be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/madeindra/interview-app/internal/elevenlabs"
+	"github.com/madeindra/interview-app/internal/model"
+	"github.com/madeindra/interview-app/internal/provider"
+)
+
+const (
+	// previewText is spoken when the preview is requested without a text
+	previewText = "Hello, thank you for joining. I will be your interviewer today."
+
+	previewMaxLength = 300
+)
+
+// voiceCache holds the elevenlabs voices of the loaded providers, it is replaced with them
+type voiceCache struct {
+	mu        sync.Mutex
+	voices    []model.Voice
+	expiresAt time.Time
+}
+
+// elevenLabs returns the elevenlabs synthesizer, whether it is in the speech chain or covers its languages
+func (p providers) elevenLabs() (*elevenlabs.ElevenLab, bool) {
+	for _, s := range append(slices.Clone(p.speech), p.coverage...) {
+		if el, ok := s.provider.(*elevenlabs.ElevenLab); ok {
+			return el, true
+		}
+	}
+
+	return nil, false
+}
+
+// listVoices returns the cached voice catalogue, fetching it when missing or expired
+func (p providers) listVoices(ctx context.Context) ([]model.Voice, error) {
+	el, ok := p.elevenLabs()
+	if !ok {
+		return nil, fmt.Errorf("elevenlabs is not configured")
+	}
+
+	p.voices.mu.Lock()
+	defer p.voices.mu.Unlock()
+
+	if p.voices.voices != nil && time.Now().Before(p.voices.expiresAt) {
+		return p.voices.voices, nil
+	}
+
+	elVoices, err := el.Voices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make([]model.Voice, 0, len(elVoices))
+	for _, v := range elVoices {
+		voices = append(voices, model.Voice{
+			ID:         v.VoiceID,
+			Name:       v.Name,
+			Category:   v.Category,
+			Labels:     v.Labels,
+			PreviewURL: v.PreviewURL,
+		})
+	}
+
+	p.voices.voices = voices
+	p.voices.expiresAt = time.Now().Add(modelCacheTTL)
+
+	return voices, nil
+}
+
+// sessionSpeech applies the voice chosen for the interview when it is spoken by elevenlabs
+func sessionSpeech(synthesizer provider.SpeechSynthesizer, options model.VoiceOptions) provider.SpeechSynthesizer {
+	el, ok := synthesizer.(*elevenlabs.ElevenLab)
+	if !ok || options == (model.VoiceOptions{}) {
+		return synthesizer
+	}
+
+	_, setting := el.Voice()
+	if options.Stability != nil {
+		setting.Stability = *options.Stability
+	}
+
+	if options.Similarity != nil {
+		setting.SimilarityBoost = *options.Similarity
+	}
+
+	if options.Style != nil {
+		setting.Style = *options.Style
+	}
+
+	return el.Clone(
+		elevenlabs.WithVoice(options.Voice),
+		elevenlabs.WithVoiceSetting(setting),
+	)
+}
+
+// validateVoiceOptions checks the ranges of the voice setting and that the voice is in the catalogue,
+// the voice is accepted when the catalogue can't be fetched
+func validateVoiceOptions(ctx context.Context, p providers, options model.VoiceOptions) error {
+	for _, v := range []*float32{options.Stability, options.Similarity, options.Style} {
+		if v != nil && (*v < 0 || *v > 1) {
+			return fmt.Errorf("voice stability, similarity and style must be between 0 and 1")
+		}
+	}
+
+	if options.Voice == "" {
+		return nil
+	}
+
+	voices, err := p.listVoices(ctx)
+	if err != nil {
+		log.Default().Println("failed to list voices:", err)
+
+		return nil
+	}
+
+	if !slices.ContainsFunc(voices, func(v model.Voice) bool { return v.ID == options.Voice }) {
+		return fmt.Errorf("the voice %s is not available, please pick another one", options.Voice)
+	}
+
+	return nil
+}
+
+// previewSetting points the speech settings at the provider and voice to preview, an empty provider is the configured one.
+// The base url, model and voice only apply to the provider they were set for, except for azure
+// which can only be reached through the endpoint and deployment of the settings
+func previewSetting(setting model.Setting, providerName, voiceID string) model.Setting {
+	if providerName == "" {
+		providerName = setting.SpeechProvider
+	}
+
+	if providerName != setting.SpeechProvider {
+		endpoint, deployment := setting.SpeechBaseURL, setting.SpeechModel
+		setting = fallbackSetting(setting, provider.CAPABILITY_SPEECH, provider.Name(providerName))
+
+		if provider.Name(providerName) == provider.PROVIDER_AZURE {
+			setting.SpeechBaseURL, setting.SpeechModel = endpoint, deployment
+		}
+	}
+
+	if voiceID == "" {
+		return setting
+	}
+
+	// a piper voice is its onnx model
+	if provider.Name(providerName) == provider.PROVIDER_PIPER {
+		setting.PiperModel = voiceID
+	} else {
+		setting.SpeechVoice = voiceID
+	}
+
+	return setting
+}